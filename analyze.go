@@ -0,0 +1,279 @@
+package golottie
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Complexity contains animation complexity metrics collected by [Analyze].
+type Complexity struct {
+	Layers      int `json:"layers"`
+	Precomps    int `json:"precomps"`
+	Shapes      int `json:"shapes"`
+	Paths       int `json:"paths"`
+	Vertices    int `json:"vertices"`
+	Masks       int `json:"masks"`
+	Mattes      int `json:"mattes"`
+	Images      int `json:"images"`
+	ImageBytes  int `json:"imageBytes"`
+	Expressions int `json:"expressions"`
+	// Frames contains measured frame render times, see [Renderer.Profile].
+	Frames []FrameTiming `json:"frames,omitempty"`
+}
+
+// FrameTiming is time spent by the player to render the frame.
+type FrameTiming struct {
+	Frame    int           `json:"frame"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Budget contains complexity thresholds, zero values are not checked.
+type Budget struct {
+	MaxLayers      int `json:"maxLayers,omitempty"`
+	MaxShapes      int `json:"maxShapes,omitempty"`
+	MaxVertices    int `json:"maxVertices,omitempty"`
+	MaxMasks       int `json:"maxMasks,omitempty"`
+	MaxMattes      int `json:"maxMattes,omitempty"`
+	MaxImageBytes  int `json:"maxImageBytes,omitempty"`
+	MaxExpressions int `json:"maxExpressions,omitempty"`
+	// MaxFrameTime is the frame render time limit in milliseconds.
+	MaxFrameTime float64 `json:"maxFrameTime,omitempty"`
+}
+
+// Violation is a complexity metric exceeding the budget.
+type Violation struct {
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	Limit  float64 `json:"limit"`
+}
+
+// String returns a human-readable violation description.
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %g exceeds budget of %g", v.Metric, v.Value, v.Limit)
+}
+
+// Analyze computes complexity metrics of the animation data.
+// Precomps are counted once per precomp layer referencing them.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("animation.json")
+//	complexity, err := golottie.Analyze(data)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, v := range complexity.Check(golottie.Budget{MaxLayers: 50}) {
+//		log.Println(v)
+//	}
+func Analyze(data []byte) (*Complexity, error) {
+	c, err := ParseComposition(data)
+	if err != nil {
+		return nil, err
+	}
+	complexity := &Complexity{}
+	for _, asset := range c.Assets {
+		if asset.IsPrecomp() {
+			continue
+		}
+		complexity.Images++
+		complexity.ImageBytes += dataURISize(asset.Path)
+	}
+	complexity.layers(c, c.Layers, map[string]bool{})
+	return complexity, nil
+}
+
+// Analyze computes complexity metrics of the initial animation data, see [Analyze].
+func (a *AnimationData) Analyze() (*Complexity, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return Analyze(a.data)
+}
+
+func (c *Complexity) layers(comp *Composition, layers []Layer, visited map[string]bool) {
+	for _, layer := range layers {
+		c.Layers++
+		c.Masks += len(layer.Masks)
+		if layer.MatteMode != 0 {
+			c.Mattes++
+		}
+		var raw interface{}
+		//nolint:errcheck // the layer has already been parsed
+		json.Unmarshal(layer.Raw, &raw)
+		c.Expressions += countExpressionsIn(raw)
+		if m, ok := raw.(map[string]interface{}); ok {
+			c.Vertices += countVertices(m["masksProperties"], "pt")
+			c.shapes(m["shapes"])
+		}
+		if layer.Type != LayerPrecomp || visited[layer.RefID] {
+			continue
+		}
+		if precomp := comp.Precomp(layer.RefID); precomp != nil {
+			c.Precomps++
+			visited[layer.RefID] = true
+			c.layers(comp, precomp.Layers, visited)
+			delete(visited, layer.RefID)
+		}
+	}
+}
+
+func (c *Complexity) shapes(shapes interface{}) {
+	walkJSON(shapes, func(shape map[string]interface{}) {
+		ty, ok := shape["ty"].(string)
+		if !ok {
+			return
+		}
+		switch ty {
+		case "sh":
+			c.Shapes++
+			c.Paths++
+			c.Vertices += countVertices(shape, "ks")
+		case "rc", "el", "sr":
+			c.Shapes++
+		}
+	})
+}
+
+// countVertices counts path vertices of the property under key of every
+// object in v. Animated paths are counted by their largest keyframe.
+func countVertices(v interface{}, key string) int {
+	count := 0
+	walkJSON(v, func(m map[string]interface{}) {
+		prop, ok := m[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		switch k := prop["k"].(type) {
+		case map[string]interface{}:
+			vertices, _ := k["v"].([]interface{})
+			count += len(vertices)
+		case []interface{}:
+			max := 0
+			for _, kf := range k {
+				keyframe, _ := kf.(map[string]interface{})
+				values, _ := keyframe["s"].([]interface{})
+				for _, value := range values {
+					path, _ := value.(map[string]interface{})
+					if vertices, _ := path["v"].([]interface{}); len(vertices) > max {
+						max = len(vertices)
+					}
+				}
+			}
+			count += max
+		}
+	})
+	return count
+}
+
+// dataURISize returns decoded size of base64 data URI or 0.
+func dataURISize(uri string) int {
+	if !strings.HasPrefix(uri, "data:") {
+		return 0
+	}
+	i := strings.Index(uri, ";base64,")
+	if i < 0 {
+		return len(uri) - strings.Index(uri, ",") - 1
+	}
+	return len(strings.TrimRight(uri[i+len(";base64,"):], "=")) * 3 / 4
+}
+
+// Check returns metrics exceeding the budget. Frame time is checked
+// only if frame timings have been measured.
+func (c *Complexity) Check(b Budget) []Violation {
+	var violations []Violation
+	check := func(metric string, value, limit int) {
+		if limit > 0 && value > limit {
+			violations = append(violations, Violation{metric, float64(value), float64(limit)})
+		}
+	}
+	check("layers", c.Layers, b.MaxLayers)
+	check("shapes", c.Shapes, b.MaxShapes)
+	check("vertices", c.Vertices, b.MaxVertices)
+	check("masks", c.Masks, b.MaxMasks)
+	check("mattes", c.Mattes, b.MaxMattes)
+	check("imageBytes", c.ImageBytes, b.MaxImageBytes)
+	check("expressions", c.Expressions, b.MaxExpressions)
+	worst := WorstFrames(c.Frames, 1)
+	if len(worst) > 0 && b.MaxFrameTime > 0 {
+		if ms := float64(worst[0].Duration) / float64(time.Millisecond); ms > b.MaxFrameTime {
+			violations = append(violations, Violation{
+				Metric: fmt.Sprintf("frame %d time (ms)", worst[0].Frame),
+				Value:  ms,
+				Limit:  b.MaxFrameTime,
+			})
+		}
+	}
+	return violations
+}
+
+// WriteText writes human-readable metrics to w including up to
+// worst slowest frames if frame timings have been measured.
+func (c *Complexity) WriteText(w io.Writer, worst int) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+	fmt.Fprintf(tw, "Layers:\t%d\n", c.Layers)
+	fmt.Fprintf(tw, "Precomps:\t%d\n", c.Precomps)
+	fmt.Fprintf(tw, "Shapes:\t%d\n", c.Shapes)
+	fmt.Fprintf(tw, "Paths:\t%d\n", c.Paths)
+	fmt.Fprintf(tw, "Vertices:\t%d\n", c.Vertices)
+	fmt.Fprintf(tw, "Masks:\t%d\n", c.Masks)
+	fmt.Fprintf(tw, "Mattes:\t%d\n", c.Mattes)
+	fmt.Fprintf(tw, "Images:\t%d (%d bytes embedded)\n", c.Images, c.ImageBytes)
+	fmt.Fprintf(tw, "Expressions:\t%d\n", c.Expressions)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(c.Frames) == 0 {
+		return nil
+	}
+	var total time.Duration
+	for _, f := range c.Frames {
+		total += f.Duration
+	}
+	fmt.Fprintf(w, "\nFrames: %d, average %s\n", len(c.Frames), total/time.Duration(len(c.Frames)))
+	for _, f := range WorstFrames(c.Frames, worst) {
+		fmt.Fprintf(w, "  frame %d\t%s\n", f.Frame, f.Duration)
+	}
+	return nil
+}
+
+// WorstFrames returns up to n slowest frames, slowest first.
+func WorstFrames(timings []FrameTiming, n int) []FrameTiming {
+	worst := append([]FrameTiming(nil), timings...)
+	sort.SliceStable(worst, func(i, j int) bool {
+		return worst[i].Duration > worst[j].Duration
+	})
+	if n < len(worst) {
+		worst = worst[:n]
+	}
+	return worst
+}
+
+// Profile renders the remaining animation frames measuring time spent
+// by the player inside the browser to render each of them.
+func (r *Renderer) Profile() ([]FrameTiming, error) {
+	var timings []FrameTiming
+	for ; r.framesDone < r.framesTotal; r.framesDone++ {
+		var ms float64
+		if err := chromedp.Run(r.ctx,
+			chromedp.Evaluate(fmt.Sprintf(`(() => {
+				const start = performance.now();
+				anim.goToAndStop(%d, true);
+				return performance.now() - start;
+			})()`, r.framesDone), &ms),
+		); err != nil {
+			return timings, err
+		}
+		timings = append(timings, FrameTiming{
+			Frame:    r.framesDone,
+			Duration: time.Duration(ms * float64(time.Millisecond)),
+		})
+	}
+	return timings, nil
+}
@@ -0,0 +1,69 @@
+package golottie
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var shapeAnimData = []byte(`{"v":"5.7.4","fr":30,"ip":0,"op":30,"w":100,"h":100,
+"assets":[{"id":"img","w":1,"h":1,"e":1,"p":"data:image/png;base64,iVBORw0KGgo="}],
+"layers":[
+{"ind":1,"ty":4,"ip":0,"op":30,"st":0,"ks":{},"tt":1,
+"masksProperties":[{"mode":"a","pt":{"a":0,"k":{"i":[[0,0],[0,0],[0,0]],"o":[[0,0],[0,0],[0,0]],"v":[[0,0],[10,0],[10,10]],"c":true}}}],
+"shapes":[{"ty":"gr","it":[
+{"ty":"sh","ks":{"a":0,"k":{"i":[[0,0],[0,0]],"o":[[0,0],[0,0]],"v":[[0,0],[1,1]],"c":false}}},
+{"ty":"sh","ks":{"a":1,"k":[{"t":0,"s":[{"v":[[0,0],[1,1],[2,2],[3,3]]}]},{"t":10,"s":[{"v":[[0,0],[1,1]]}]}]}},
+{"ty":"el"},{"ty":"fl"}]}]},
+{"ind":2,"ty":2,"refId":"img","ip":0,"op":30,"st":0,"ks":{"o":{"a":0,"k":100,"x":"value"}}}
+]}`)
+
+func Test_Analyze(t *testing.T) {
+	complexity, err := Analyze(shapeAnimData)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, complexity.Layers)
+	assert.Equal(t, 3, complexity.Shapes)
+	assert.Equal(t, 2, complexity.Paths)
+	assert.Equal(t, 9, complexity.Vertices)
+	assert.Equal(t, 1, complexity.Masks)
+	assert.Equal(t, 1, complexity.Mattes)
+	assert.Equal(t, 1, complexity.Images)
+	assert.Equal(t, 8, complexity.ImageBytes)
+	assert.Equal(t, 1, complexity.Expressions)
+
+	t.Run("Precomps", func(t *testing.T) {
+		complexity, err := NewAnimation(animData).Analyze()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, complexity.Layers)
+		assert.Equal(t, 1, complexity.Precomps)
+	})
+	t.Run("Budget", func(t *testing.T) {
+		assert.Empty(t, complexity.Check(Budget{}))
+		violations := complexity.Check(Budget{MaxLayers: 1, MaxVertices: 100, MaxExpressions: 1})
+		assert.Len(t, violations, 1)
+		assert.Equal(t, "layers", violations[0].Metric)
+
+		complexity.Frames = []FrameTiming{{0, time.Millisecond}, {1, 20 * time.Millisecond}, {2, 5 * time.Millisecond}}
+		violations = complexity.Check(Budget{MaxFrameTime: 16})
+		assert.Len(t, violations, 1)
+		assert.Equal(t, 20.0, violations[0].Value)
+
+		var buf bytes.Buffer
+		assert.NoError(t, complexity.WriteText(&buf, 2))
+		assert.Contains(t, buf.String(), "frame 1")
+		assert.NotContains(t, buf.String(), "frame 0")
+	})
+	t.Run("Bad_animation", func(t *testing.T) {
+		_, err := Analyze(nil)
+		assert.ErrorIs(t, err, ErrNilAnimationData)
+	})
+}
+
+func Test_WorstFrames(t *testing.T) {
+	timings := []FrameTiming{{0, 3}, {1, 1}, {2, 2}}
+	assert.Equal(t, []FrameTiming{{0, 3}, {2, 2}}, WorstFrames(timings, 2))
+	assert.Len(t, WorstFrames(timings, 10), 3)
+	assert.Equal(t, 0, timings[0].Frame, "timings shouldn't be sorted in place")
+}
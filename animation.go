@@ -20,17 +20,26 @@ type AnimationData struct {
 	// Equals to nil if animation template hasn't been initialized.
 	Template *template.Template
 
+	data        []byte
+	name        string
 	framesTotal int
+	frameRate   float64
+	width       int
+	height      int
 	server      *httptest.Server
 	buf         *bytes.Buffer
+	// err is the error decoding the data, returned when the animation
+	// is initialized.
+	err error
 }
 
 //go:embed templates/default.gohtml
 var defTemplate embed.FS
 
 // NewAnimation creates a new animation with data argument as animation data.
+// Data can be either Lottie JSON or gzip compressed Telegram sticker (.tgs).
 // Template function should be called on resulting animation for it to be
-// completely initialized.
+// completely initialized, it returns the error decoding the sticker.
 //
 // Example:
 //
@@ -41,15 +50,38 @@ var defTemplate embed.FS
 //	}
 //	renderer.SetAnimation(animation)
 func NewAnimation(data []byte) *AnimationData {
-	return &AnimationData{
-		buf:         bytes.NewBuffer(data),
-		framesTotal: gson.New(data).Get("op").Int(),
+	a := &AnimationData{}
+	a.err = a.setData(data)
+	return a
+}
+
+// setData resets animation to the provided animation data decompressing
+// Telegram stickers. The data is copied since the buffer is reused by the templates.
+func (a *AnimationData) setData(data []byte) error {
+	if IsTGS(data) {
+		decoded, err := DecodeTGS(data)
+		if err != nil {
+			return err
+		}
+		data = decoded
 	}
+	j := gson.New(data)
+	a.data = data
+	a.buf = bytes.NewBuffer(append([]byte(nil), data...))
+	a.name = str(j.Get("nm"))
+	a.framesTotal = j.Get("op").Int()
+	a.frameRate = j.Get("fr").Num()
+	a.width = j.Get("w").Int()
+	a.height = j.Get("h").Int()
+	return nil
 }
 
 // WithDefaultTemplate initializes animation data using embedded default template.
 // Returns an error if the initial data is nil or has 0 length.
 func (a *AnimationData) WithDefaultTemplate() (animation *AnimationData, err error) {
+	if a.err != nil {
+		return a, fmt.Errorf("error creating new animation: %w", a.err)
+	}
 	if a.buf == nil || a.buf.Len() <= 0 {
 		return a, fmt.Errorf("error creating new animation: %w", ErrNilAnimationData)
 	}
@@ -85,6 +117,9 @@ func (a *AnimationData) WithCustomTemplate(templ *template.Template, data map[st
 	if templ == nil {
 		return a, fmt.Errorf("Error parsing custom template: %w", ErrNilTemplate)
 	}
+	if a.err != nil {
+		return a, fmt.Errorf("error creating new animation: %w", a.err)
+	}
 	if data == nil {
 		data = make(map[string]interface{})
 	}
@@ -110,6 +145,32 @@ func (a *AnimationData) GetFramesTotal() int {
 	return a.framesTotal
 }
 
+// GetWidth returns animation width as specified by animation data.
+func (a *AnimationData) GetWidth() int {
+	return a.width
+}
+
+// GetHeight returns animation height as specified by animation data.
+func (a *AnimationData) GetHeight() int {
+	return a.height
+}
+
+// GetFrameRate returns animation frame rate as specified by animation data.
+func (a *AnimationData) GetFrameRate() float64 {
+	return a.frameRate
+}
+
+// Metadata returns animation metadata passed to frame sinks.
+func (a *AnimationData) Metadata() Metadata {
+	return Metadata{
+		Name:        a.name,
+		Width:       a.width,
+		Height:      a.height,
+		FrameRate:   a.frameRate,
+		FramesTotal: a.framesTotal,
+	}
+}
+
 // Close closes the local server if it exists.
 func (a *AnimationData) Close() {
 	if a.server != nil {
@@ -0,0 +1,370 @@
+package golottie
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AnimatedSVGMode selects how frames of an animated SVG are switched.
+type AnimatedSVGMode int
+
+const (
+	// AnimatedSVGCSS switches frames with CSS keyframes.
+	AnimatedSVGCSS AnimatedSVGMode = iota
+	// AnimatedSVGSMIL switches frames with SMIL animate elements,
+	// which play where style elements are stripped.
+	AnimatedSVGSMIL
+)
+
+var svgImageElemRe = regexp.MustCompile(`<image\b[^>]*?(?:/>|>\s*</image>)`)
+
+// AnimatedSVGOptions configures [AnimatedSVGSink].
+type AnimatedSVGOptions struct {
+	Mode AnimatedSVGMode
+	// LoopCount is the number of times the animation is repeated,
+	// 0 loops forever and -1 plays it once.
+	LoopCount int
+	// Step keeps every n-th frame.
+	Step int
+	// Assets, Fonts and Minify are applied to the document as by [StandaloneSVG].
+	Assets fs.FS
+	Fonts  map[string][]byte
+	Minify bool
+}
+
+// DefaultAnimatedSVGOptions loops the animation forever switching every
+// frame with CSS keyframes.
+var DefaultAnimatedSVGOptions = AnimatedSVGOptions{Mode: AnimatedSVGCSS, Step: 1}
+
+// svgFrameSlot is a frame of the timeline showing one of the unique frames.
+type svgFrameSlot struct {
+	num   int
+	group int
+}
+
+// AnimatedSVGSink combines SVG frames captured with [FormatSVG] into a
+// single animated SVG which plays without JavaScript. Every unique frame
+// is a group whose visibility is switched at the frame times. Identical
+// frames are stored once and definitions shared by frames, such as clip
+// paths and images, are merged, element ids are renamed to stay unique.
+// Without animation support the first frame is shown.
+//
+// Example:
+//
+//	sink := golottie.NewAnimatedSVGSink(f, golottie.DefaultAnimatedSVGOptions)
+//	sink.Open(animation.Metadata())
+//	for renderer.NextFrame() {
+//		renderer.RenderFrameAs(&buf, golottie.FormatSVG, 0)
+//		sink.WriteFrame(golottie.Frame{Num: n, Data: buf})
+//	}
+//	err := sink.Close()
+type AnimatedSVGSink struct {
+	w    io.Writer
+	opts AnimatedSVGOptions
+	meta Metadata
+
+	rootAttrs string
+	slots     []svgFrameSlot
+	groups    []string
+	frameKeys map[[sha256.Size]byte]int
+	defs      []string
+	defKeys   map[[sha256.Size]byte]string
+	images    []string
+	imageIDs  map[string]string
+}
+
+// NewAnimatedSVGSink returns animated SVG sink writing to w.
+func NewAnimatedSVGSink(w io.Writer, opts AnimatedSVGOptions) *AnimatedSVGSink {
+	if opts.Step < 1 {
+		opts.Step = 1
+	}
+	return &AnimatedSVGSink{w: w, opts: opts}
+}
+
+// FrameFormat requests SVG markup of the frames.
+func (s *AnimatedSVGSink) FrameFormat() (ImageFormat, int) {
+	return FormatSVG, 0
+}
+
+// Open prepares the sink for the animation.
+func (s *AnimatedSVGSink) Open(meta Metadata) error {
+	s.meta = meta
+	s.rootAttrs = ""
+	s.slots, s.groups, s.defs, s.images = nil, nil, nil, nil
+	s.frameKeys = make(map[[sha256.Size]byte]int)
+	s.defKeys = make(map[[sha256.Size]byte]string)
+	s.imageIDs = make(map[string]string)
+	return nil
+}
+
+// WriteFrame adds SVG frame to the timeline, frames not matching Step
+// are skipped.
+func (s *AnimatedSVGSink) WriteFrame(f Frame) error {
+	if f.Num%s.opts.Step != 0 {
+		return nil
+	}
+	svg := string(f.Data)
+	root := svgRootRe.FindStringSubmatchIndex(svg)
+	if root == nil {
+		return fmt.Errorf("error reading svg frame %d: root svg element not found", f.Num)
+	}
+	if s.rootAttrs == "" {
+		s.rootAttrs = svg[root[2]:root[3]]
+	}
+	body := strings.TrimSuffix(strings.TrimSpace(svg[root[1]:]), "</svg>")
+	key := sha256.Sum256([]byte(body))
+	if group, ok := s.frameKeys[key]; ok {
+		s.slots = append(s.slots, svgFrameSlot{num: f.Num, group: group})
+		return nil
+	}
+	group := len(s.groups)
+	s.frameKeys[key] = group
+	s.slots = append(s.slots, svgFrameSlot{num: f.Num, group: group})
+
+	ids := make(map[string]string)
+	var defs, content []string
+	for _, child := range svgChildren(body) {
+		if !strings.HasPrefix(child, "<defs") {
+			for _, m := range svgIDRe.FindAllStringSubmatch(child, -1) {
+				ids[m[1]] = fmt.Sprintf("f%d-%s", group, m[1])
+			}
+			content = append(content, child)
+			continue
+		}
+		inner := strings.TrimSuffix(child[strings.IndexByte(child, '>')+1:], "</defs>")
+		for _, def := range svgChildren(inner) {
+			if s.mergeDef(def, group, ids) {
+				defs = append(defs, def)
+			}
+		}
+	}
+	rename := func(id string) string {
+		if renamed, ok := ids[id]; ok {
+			return renamed
+		}
+		return id
+	}
+	for _, def := range defs {
+		s.defs = append(s.defs, s.hoistImages(renameSVGIDs(def, rename)))
+	}
+	s.groups = append(s.groups, s.hoistImages(renameSVGIDs(strings.Join(content, ""), rename)))
+	return nil
+}
+
+// mergeDef maps ids of the definition to the ones of an identical
+// definition seen before, otherwise the ids are renamed to new ones and
+// true is returned. Definitions referencing elements which are not
+// mapped yet are never merged.
+func (s *AnimatedSVGSink) mergeDef(def string, group int, ids map[string]string) bool {
+	var own []string
+	for _, m := range svgIDRe.FindAllStringSubmatch(def, -1) {
+		own = append(own, m[1])
+	}
+	isOwn := func(id string) bool {
+		for _, o := range own {
+			if o == id {
+				return true
+			}
+		}
+		return false
+	}
+	// the key includes the ids references are mapped to, so definitions
+	// referencing different elements are kept apart
+	key := def
+	var refs [][]string
+	refs = append(refs, svgURLRe.FindAllStringSubmatch(def, -1)...)
+	refs = append(refs, svgHrefRe.FindAllStringSubmatch(def, -1)...)
+	for _, m := range refs {
+		if isOwn(m[2]) {
+			continue
+		}
+		mapped, ok := ids[m[2]]
+		if !ok {
+			mapped = "?" + strconv.Itoa(group)
+		}
+		key += "\x00" + mapped
+	}
+	sum := sha256.Sum256([]byte(key))
+	prefix, ok := s.defKeys[sum]
+	if !ok {
+		prefix = fmt.Sprintf("d%d-", len(s.defKeys))
+		s.defKeys[sum] = prefix
+	}
+	for _, id := range own {
+		ids[id] = prefix + id
+	}
+	return !ok
+}
+
+// hoistImages moves image elements into the shared definitions replacing
+// them with references, so images repeated in frames are stored once.
+func (s *AnimatedSVGSink) hoistImages(svg string) string {
+	return svgImageElemRe.ReplaceAllStringFunc(svg, func(image string) string {
+		if svgIDRe.MatchString(image) {
+			return image
+		}
+		id, ok := s.imageIDs[image]
+		if !ok {
+			id = fmt.Sprintf("img%d", len(s.images))
+			s.imageIDs[image] = id
+			s.images = append(s.images, strings.Replace(image, "<image", `<image id="`+id+`"`, 1))
+		}
+		return `<use xlink:href="#` + id + `"></use>`
+	})
+}
+
+// Close writes the animated SVG.
+func (s *AnimatedSVGSink) Close() error {
+	if len(s.slots) == 0 {
+		return ErrNoFrames
+	}
+	sort.SliceStable(s.slots, func(i, j int) bool { return s.slots[i].num < s.slots[j].num })
+	first := s.slots[0].num
+	total := s.meta.FramesTotal
+	if last := s.slots[len(s.slots)-1].num; total <= last {
+		total = last + 1
+	}
+	duration := formatFraction(float64(total-first)/s.meta.frameRateOr30(), 3) + "s"
+	iterations := "indefinite"
+	if s.opts.LoopCount < 0 {
+		iterations = "1"
+	} else if s.opts.LoopCount > 0 {
+		iterations = strconv.Itoa(s.opts.LoopCount + 1)
+	}
+
+	var b strings.Builder
+	b.WriteString("<svg " + s.rootAttrs + ">")
+	if len(s.defs)+len(s.images) > 0 {
+		b.WriteString("<defs>" + strings.Join(s.images, "") + strings.Join(s.defs, "") + "</defs>")
+	}
+	timeline := make([][]svgKeyframe, len(s.groups))
+	for g := range s.groups {
+		timeline[g] = s.keyframes(g, first, total)
+	}
+	if s.opts.Mode == AnimatedSVGCSS && len(s.groups) > 1 {
+		cssIterations := iterations
+		if cssIterations == "indefinite" {
+			cssIterations = "infinite"
+		}
+		b.WriteString("<style>")
+		for g, keyframes := range timeline {
+			fmt.Fprintf(&b, "@keyframes g%d{", g)
+			for _, k := range keyframes {
+				fmt.Fprintf(&b, "%s%%{visibility:%s}", formatFraction(k.at*100, 4), k.visibility())
+			}
+			fmt.Fprintf(&b, "}.g%[1]d{animation:g%[1]d %[2]s step-end %[3]s forwards}", g, duration, cssIterations)
+		}
+		b.WriteString("</style>")
+	}
+	for g, group := range s.groups {
+		visibility := timeline[g][0].visibility()
+		fmt.Fprintf(&b, `<g class="g%d" visibility="%s">`, g, visibility)
+		if s.opts.Mode == AnimatedSVGSMIL && len(s.groups) > 1 {
+			values := make([]string, len(timeline[g]))
+			times := make([]string, len(timeline[g]))
+			for i, k := range timeline[g] {
+				values[i], times[i] = k.visibility(), formatFraction(k.at, 6)
+			}
+			fmt.Fprintf(&b, `<animate attributeName="visibility" values="%s" keyTimes="%s" dur="%s" calcMode="discrete" repeatCount="%s" fill="freeze"></animate>`,
+				strings.Join(values, ";"), strings.Join(times, ";"), duration, iterations)
+		}
+		b.WriteString(group + "</g>")
+	}
+	b.WriteString("</svg>")
+
+	doc, err := StandaloneSVG(b.String(), s.meta, SVGOptions{
+		Assets: s.opts.Assets,
+		Fonts:  s.opts.Fonts,
+		Minify: s.opts.Minify,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(s.w, doc)
+	return err
+}
+
+// svgKeyframe is a visibility change at a fraction of the duration.
+type svgKeyframe struct {
+	at      float64
+	visible bool
+}
+
+func (k svgKeyframe) visibility() string {
+	if k.visible {
+		return "visible"
+	}
+	return "hidden"
+}
+
+// keyframes returns visibility changes of the group, the last keyframe
+// at 1 holds the state the animation ends with.
+func (s *AnimatedSVGSink) keyframes(group, first, total int) []svgKeyframe {
+	var keyframes []svgKeyframe
+	for i, slot := range s.slots {
+		visible := slot.group == group
+		if i > 0 && keyframes[len(keyframes)-1].visible == visible {
+			continue
+		}
+		at := float64(slot.num-first) / float64(total-first)
+		keyframes = append(keyframes, svgKeyframe{at: at, visible: visible})
+	}
+	return append(keyframes, svgKeyframe{at: 1, visible: keyframes[len(keyframes)-1].visible})
+}
+
+// formatFraction formats v with up to prec decimals.
+func formatFraction(v float64, prec int) string {
+	return strings.TrimSuffix(strings.TrimRight(strconv.FormatFloat(v, 'f', prec, 64), "0"), ".")
+}
+
+// svgChildren splits SVG markup into top level elements, text and
+// comments between them are dropped.
+func svgChildren(svg string) []string {
+	var children []string
+	depth, start := 0, 0
+	for i := 0; i < len(svg); {
+		lt := strings.IndexByte(svg[i:], '<')
+		if lt < 0 {
+			break
+		}
+		lt += i
+		if strings.HasPrefix(svg[lt:], "<!--") {
+			end := strings.Index(svg[lt:], "-->")
+			if end < 0 {
+				break
+			}
+			i = lt + end + 3
+			continue
+		}
+		gt := strings.IndexByte(svg[lt:], '>')
+		if gt < 0 {
+			break
+		}
+		gt += lt + 1
+		tag := svg[lt:gt]
+		switch {
+		case strings.HasPrefix(tag, "</"):
+			depth--
+			if depth == 0 {
+				children = append(children, svg[start:gt])
+			}
+		case strings.HasSuffix(tag, "/>"):
+			if depth == 0 {
+				children = append(children, tag)
+			}
+		default:
+			if depth == 0 {
+				start = lt
+			}
+			depth++
+		}
+		i = gt
+	}
+	return children
+}
@@ -0,0 +1,79 @@
+package golottie
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// svgFrame returns player-like SVG frame with the shared clip path,
+// image and the path data of the frame.
+func svgFrame(d string) []byte {
+	return []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10" width="100%" height="100%" style="width: 100%;">` +
+		`<defs><clipPath id="__lottie_element_2"><rect width="10" height="10"></rect></clipPath>` +
+		`<mask id="__lottie_element_4"><path d="` + d + `"></path></mask></defs>` +
+		`<g clip-path="url(#__lottie_element_2)"><g mask="url(#__lottie_element_4)">` +
+		`<image width="10" height="10" href="data:image/png;base64,AAAA"></image></g></g></svg>`)
+}
+
+func writeAnimatedSVG(t *testing.T, opts AnimatedSVGOptions, frames ...[]byte) string {
+	var buf bytes.Buffer
+	sink := NewAnimatedSVGSink(&buf, opts)
+	require.NoError(t, sink.Open(Metadata{Width: 10, Height: 10, FrameRate: 10, FramesTotal: 4}))
+	for i, f := range frames {
+		require.NoError(t, sink.WriteFrame(Frame{Num: i, Data: f}))
+	}
+	require.NoError(t, sink.Close())
+	d := xml.NewDecoder(strings.NewReader(buf.String()))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	return buf.String()
+}
+
+func Test_AnimatedSVGSink(t *testing.T) {
+	a, b := svgFrame("M0,0 L1,1"), svgFrame("M0,0 L2,2")
+	doc := writeAnimatedSVG(t, DefaultAnimatedSVGOptions, a, b, b, a)
+
+	assert.Equal(t, 2, strings.Count(doc, "<g class="), "identical frames are stored once")
+	assert.Equal(t, 1, strings.Count(doc, "<clipPath"), "identical defs are merged")
+	assert.Equal(t, 2, strings.Count(doc, "<mask"))
+	assert.Equal(t, 1, strings.Count(doc, "<image"), "images are hoisted")
+	assert.Equal(t, 2, strings.Count(doc, `<use xlink:href="#img0">`))
+	assert.Contains(t, doc, `<clipPath id="d0-__lottie_element_2">`)
+	assert.Contains(t, doc, `<mask id="d1-__lottie_element_4">`)
+	assert.Contains(t, doc, `<mask id="d2-__lottie_element_4">`)
+	assert.Equal(t, 2, strings.Count(doc, `clip-path="url(#d0-__lottie_element_2)"`))
+	assert.Contains(t, doc, `mask="url(#d2-__lottie_element_4)"`)
+	assert.Contains(t, doc, `@keyframes g0{0%{visibility:visible}25%{visibility:hidden}75%{visibility:visible}100%{visibility:visible}}`+
+		`.g0{animation:g0 0.4s step-end infinite forwards}`)
+	assert.Contains(t, doc, `@keyframes g1{0%{visibility:hidden}25%{visibility:visible}75%{visibility:hidden}100%{visibility:hidden}}`)
+	assert.Contains(t, doc, `<g class="g0" visibility="visible">`)
+	assert.Contains(t, doc, `<g class="g1" visibility="hidden">`)
+	assert.Contains(t, doc, `viewBox="0 0 10 10" width="10" height="10"`)
+
+	doc = writeAnimatedSVG(t, AnimatedSVGOptions{Mode: AnimatedSVGSMIL, LoopCount: -1, Step: 2}, a, b, b, a)
+	assert.NotContains(t, doc, "<style>")
+	assert.Equal(t, 2, strings.Count(doc, "<g class="))
+	assert.Contains(t, doc, `<g class="g0" visibility="visible"><animate attributeName="visibility" values="visible;hidden;hidden" keyTimes="0;0.5;1" dur="0.4s" calcMode="discrete" repeatCount="1" fill="freeze"></animate>`)
+
+	sink := NewAnimatedSVGSink(io.Discard, DefaultAnimatedSVGOptions)
+	require.NoError(t, sink.Open(Metadata{}))
+	assert.Error(t, sink.WriteFrame(Frame{Data: []byte("<div>")}))
+	assert.ErrorIs(t, sink.Close(), ErrNoFrames)
+}
+
+func Test_svgChildren(t *testing.T) {
+	assert.Equal(t,
+		[]string{`<g><g></g><path/></g>`, `<rect/>`, `<text>a</text>`},
+		svgChildren(` <g><g></g><path/></g><!-- <x> --><rect/> <text>a</text>`))
+}
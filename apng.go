@@ -0,0 +1,265 @@
+package golottie
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// APNGOptions configures [APNGSink].
+type APNGOptions struct {
+	// LoopCount is the number of times the animation is repeated,
+	// 0 loops forever and -1 shows every frame once.
+	LoopCount int
+	// Crop stores only the region changed since the previous frame,
+	// frames without changes extend the previous frame delay. Holds
+	// longer than a delay can express continue in a 1x1 frame.
+	Crop bool
+}
+
+// DefaultAPNGOptions loops the animation forever cropping unchanged regions.
+var DefaultAPNGOptions = APNGOptions{Crop: true}
+
+// APNGSink assembles rendered PNG frames into an animated PNG keeping
+// full alpha. Frames must be written in order, encoded frames are
+// buffered and the APNG is written to w on [APNGSink.Close] since
+// the frame count precedes the frames.
+type APNGSink struct {
+	w      io.Writer
+	opts   APNGOptions
+	meta   Metadata
+	prev   *image.NRGBA
+	frames []apngFrame
+	// delayNum/delayDen is a single frame duration in seconds.
+	delayNum, delayDen int
+}
+
+type apngFrame struct {
+	rect image.Rectangle
+	// count is the number of animation frames the frame is shown for.
+	count int
+	data  []byte
+}
+
+// NewAPNGSink returns APNG sink writing to w.
+func NewAPNGSink(w io.Writer, opts APNGOptions) *APNGSink {
+	return &APNGSink{w: w, opts: opts}
+}
+
+// Open prepares the sink for the animation.
+func (s *APNGSink) Open(meta Metadata) error {
+	s.meta = meta
+	s.prev = nil
+	s.frames = s.frames[:0]
+	frameRate := meta.frameRateOr30()
+	scale := 1000.0
+	for frameRate*scale > 0xffff && scale > 1 {
+		scale /= 10
+	}
+	s.delayNum, s.delayDen = int(scale), int(frameRate*scale+0.5)
+	return nil
+}
+
+// WriteFrame encodes PNG encoded frame.
+func (s *APNGSink) WriteFrame(f Frame) error {
+	src, err := png.Decode(bytes.NewReader(f.Data))
+	if err != nil {
+		return fmt.Errorf("error decoding frame %d: %w", f.Num, err)
+	}
+	b := src.Bounds().Sub(src.Bounds().Min)
+	if s.meta.Width > 0 && s.meta.Height > 0 {
+		b = image.Rect(0, 0, s.meta.Width, s.meta.Height)
+	}
+	img := image.NewNRGBA(b)
+	draw.Draw(img, b, src, src.Bounds().Min, draw.Src)
+
+	rect := b
+	if s.opts.Crop && s.prev != nil {
+		rect = changedRect(s.prev, img)
+		if rect.Empty() {
+			last := &s.frames[len(s.frames)-1]
+			if _, _, ok := s.delay(last.count + 1); ok {
+				last.count++
+				return nil
+			}
+			// the delay overflows, the hold continues in a frame
+			// repeating a single pixel
+			rect = image.Rect(b.Min.X, b.Min.Y, b.Min.X+1, b.Min.Y+1)
+		}
+	}
+	s.prev = img
+	data, err := encodeRGBA(img.SubImage(rect).(*image.NRGBA))
+	if err != nil {
+		return fmt.Errorf("error encoding frame %d: %w", f.Num, err)
+	}
+	s.frames = append(s.frames, apngFrame{rect: rect, count: 1, data: data})
+	return nil
+}
+
+// Close writes the APNG.
+func (s *APNGSink) Close() error {
+	if len(s.frames) == 0 {
+		return ErrNoFrames
+	}
+	size := s.frames[0].rect.Size()
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	writeChunk(&buf, "IHDR", u32(size.X), u32(size.Y), []byte{8, 6, 0, 0, 0})
+	plays := 0
+	if s.opts.LoopCount < 0 {
+		plays = 1
+	} else if s.opts.LoopCount > 0 {
+		plays = s.opts.LoopCount + 1
+	}
+	writeChunk(&buf, "acTL", u32(len(s.frames)), u32(plays))
+	seq := 0
+	for i, f := range s.frames {
+		num, den, _ := s.delay(f.count)
+		writeChunk(&buf, "fcTL", u32(seq),
+			u32(f.rect.Dx()), u32(f.rect.Dy()), u32(f.rect.Min.X), u32(f.rect.Min.Y),
+			u16(num), u16(den),
+			// APNG_DISPOSE_OP_NONE, APNG_BLEND_OP_SOURCE
+			[]byte{0, 0})
+		seq++
+		if i == 0 {
+			writeChunk(&buf, "IDAT", f.data)
+			continue
+		}
+		writeChunk(&buf, "fdAT", u32(seq), f.data)
+		seq++
+	}
+	writeChunk(&buf, "IEND")
+	s.frames = nil
+	_, err := buf.WriteTo(s.w)
+	return err
+}
+
+// delay returns the duration of count frames as a reduced fraction of
+// a second, ok tells whether it fits the fcTL fields.
+func (s *APNGSink) delay(count int) (num, den int, ok bool) {
+	num, den = count*s.delayNum, s.delayDen
+	g := gcd(num, den)
+	num, den = num/g, den/g
+	return num, den, num <= 0xffff && den <= 0xffff
+}
+
+// changedRect returns the bounding box of pixels differing between frames.
+func changedRect(a, b *image.NRGBA) image.Rectangle {
+	r := image.Rectangle{}
+	bounds := b.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		rowA := a.Pix[a.PixOffset(bounds.Min.X, y):a.PixOffset(bounds.Max.X, y)]
+		rowB := b.Pix[b.PixOffset(bounds.Min.X, y):b.PixOffset(bounds.Max.X, y)]
+		if bytes.Equal(rowA, rowB) {
+			continue
+		}
+		for x := 0; x < len(rowB); x += 4 {
+			if !bytes.Equal(rowA[x:x+4], rowB[x:x+4]) {
+				r = r.Union(image.Rect(bounds.Min.X+x/4, y, bounds.Min.X+x/4+1, y+1))
+			}
+		}
+	}
+	return r
+}
+
+// encodeRGBA returns zlib compressed 8-bit RGBA scanlines picking
+// the filter with the minimal sum of absolute differences for every row.
+func encodeRGBA(img *image.NRGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	stride := b.Dx() * 4
+	prev := make([]byte, stride)
+	var filtered [5][]byte
+	for i := range filtered {
+		filtered[i] = make([]byte, stride+1)
+		filtered[i][0] = byte(i)
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, y) : img.PixOffset(b.Min.X, y)+stride]
+		best, bestSum := 0, -1
+		for ft := range filtered {
+			out := filtered[ft][1:]
+			sum := 0
+			for x := range row {
+				var left, upLeft byte
+				if x >= 4 {
+					left, upLeft = row[x-4], prev[x-4]
+				}
+				up := prev[x]
+				switch ft {
+				case 0:
+					out[x] = row[x]
+				case 1:
+					out[x] = row[x] - left
+				case 2:
+					out[x] = row[x] - up
+				case 3:
+					out[x] = row[x] - byte((int(left)+int(up))/2)
+				case 4:
+					out[x] = row[x] - paeth(left, up, upLeft)
+				}
+				sum += abs(int(int8(out[x])))
+			}
+			if bestSum < 0 || sum < bestSum {
+				best, bestSum = ft, sum
+			}
+		}
+		if _, err = zw.Write(filtered[best]); err != nil {
+			return nil, err
+		}
+		prev = row
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func writeChunk(w io.Writer, name string, fields ...[]byte) {
+	var data []byte
+	for _, f := range fields {
+		data = append(data, f...)
+	}
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(name))
+	crc.Write(data)
+	//nolint:errcheck // bytes.Buffer doesn't fail
+	w.Write(append(append(append(u32(len(data)), name...), data...), u32(int(crc.Sum32()))...))
+}
+
+func u32(v int) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(v))
+}
+
+func u16(v int) []byte {
+	return binary.BigEndian.AppendUint16(nil, uint16(v))
+}
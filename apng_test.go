@@ -0,0 +1,130 @@
+package golottie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pngChunk struct {
+	name string
+	data []byte
+}
+
+func readChunks(t *testing.T, data []byte) []pngChunk {
+	assert.Equal(t, "\x89PNG\r\n\x1a\n", string(data[:8]))
+	var chunks []pngChunk
+	for data = data[8:]; len(data) >= 12; {
+		n := binary.BigEndian.Uint32(data)
+		chunks = append(chunks, pngChunk{name: string(data[4:8]), data: data[8 : 8+n]})
+		data = data[12+n:]
+	}
+	return chunks
+}
+
+func Test_APNGSink(t *testing.T) {
+	red := pngFrame(t, color.NRGBA{R: 255, A: 255})
+	green := pngFrame(t, color.NRGBA{G: 255, A: 255})
+	img, err := png.Decode(bytes.NewReader(red))
+	assert.NoError(t, err)
+	dot := image.NewNRGBA(img.Bounds())
+	draw.Draw(dot, dot.Bounds(), img, image.Point{}, draw.Src)
+	dot.SetNRGBA(2, 3, color.NRGBA{G: 255, A: 255})
+	var dotBuf bytes.Buffer
+	assert.NoError(t, png.Encode(&dotBuf, dot))
+	repeat := func(frame []byte, n int) [][]byte {
+		frames := make([][]byte, n)
+		for i := range frames {
+			frames[i] = frame
+		}
+		return frames
+	}
+	tests := []struct {
+		name      string
+		opts      APNGOptions
+		frameRate float64
+		frames    [][]byte
+		plays     uint32
+		fctl      [][6]uint32 // width, height, x, y, delay num, delay den
+	}{
+		{
+			name:   "cropped",
+			opts:   APNGOptions{Crop: true},
+			frames: [][]byte{red, red, dotBuf.Bytes(), green},
+			fctl:   [][6]uint32{{4, 4, 0, 0, 1, 12}, {1, 1, 2, 3, 1, 24}, {4, 4, 0, 0, 1, 24}},
+		},
+		{
+			name:      "static run",
+			opts:      APNGOptions{Crop: true},
+			frameRate: 30,
+			frames:    append(repeat(red, 100), green),
+			fctl:      [][6]uint32{{4, 4, 0, 0, 10, 3}, {4, 4, 0, 0, 1, 30}},
+		},
+		{
+			// 656 frames at 29.97 fps don't fit the delay fields
+			name:      "split hold",
+			opts:      APNGOptions{Crop: true},
+			frameRate: 29.97,
+			frames:    repeat(red, 700),
+			fctl:      [][6]uint32{{4, 4, 0, 0, 65500, 2997}, {1, 1, 0, 0, 500, 333}},
+		},
+		{
+			name:   "full frames",
+			opts:   APNGOptions{LoopCount: -1},
+			frames: [][]byte{red, red, green},
+			plays:  1,
+			fctl:   [][6]uint32{{4, 4, 0, 0, 1, 24}, {4, 4, 0, 0, 1, 24}, {4, 4, 0, 0, 1, 24}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			sink := NewAPNGSink(&buf, tt.opts)
+			frameRate := tt.frameRate
+			if frameRate == 0 {
+				frameRate = 24
+			}
+			assert.NoError(t, sink.Open(Metadata{Width: 4, Height: 4, FrameRate: frameRate}))
+			for i, f := range tt.frames {
+				assert.NoError(t, sink.WriteFrame(Frame{Num: i, Data: f}))
+			}
+			assert.NoError(t, sink.Close())
+
+			img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+			assert.NoError(t, err)
+			assert.Equal(t, color.NRGBA{}, img.At(0, 0))
+			assert.Equal(t, color.NRGBA{R: 255, A: 255}, img.At(1, 1))
+
+			var fctl [][6]uint32
+			seq := uint32(0)
+			for _, c := range readChunks(t, buf.Bytes()) {
+				switch c.name {
+				case "acTL":
+					assert.Equal(t, uint32(len(tt.fctl)), binary.BigEndian.Uint32(c.data))
+					assert.Equal(t, tt.plays, binary.BigEndian.Uint32(c.data[4:]))
+				case "fcTL", "fdAT":
+					assert.Equal(t, seq, binary.BigEndian.Uint32(c.data))
+					seq++
+				}
+				if c.name == "fcTL" {
+					d := c.data
+					fctl = append(fctl, [6]uint32{
+						binary.BigEndian.Uint32(d[4:]), binary.BigEndian.Uint32(d[8:]),
+						binary.BigEndian.Uint32(d[12:]), binary.BigEndian.Uint32(d[16:]),
+						uint32(binary.BigEndian.Uint16(d[20:])), uint32(binary.BigEndian.Uint16(d[22:])),
+					})
+				}
+			}
+			assert.Equal(t, tt.fctl, fctl)
+		})
+	}
+
+	err = NewAPNGSink(&bytes.Buffer{}, DefaultAPNGOptions).Close()
+	assert.ErrorIs(t, err, ErrNoFrames)
+}
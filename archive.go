@@ -0,0 +1,217 @@
+package golottie
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ArchiveManifestName is the name of the manifest entry of archives.
+const ArchiveManifestName = "manifest.json"
+
+// ArchiveOptions configures [ZipSink] and [TarSink].
+type ArchiveOptions struct {
+	// Pattern is the sprintf pattern entries are named with.
+	Pattern string
+	// Name names entries instead of Pattern and Start if it's set.
+	Name FrameNamer
+	// Start is added to zero based frame numbers.
+	Start int
+	// Manifest adds manifest.json entry describing the frames.
+	Manifest bool
+	// Gzip compresses tar archives.
+	Gzip bool
+}
+
+// DefaultArchiveOptions names entries like 0000.png and adds the manifest.
+var DefaultArchiveOptions = ArchiveOptions{Pattern: "%04d.png", Manifest: true}
+
+// ArchiveManifest describes frames of an archive.
+type ArchiveManifest struct {
+	Name       string  `json:"name,omitempty"`
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	FrameRate  float64 `json:"fps"`
+	FrameCount int     `json:"frameCount"`
+	// Frames are the entry names sorted by frame number.
+	Frames []string `json:"frames"`
+}
+
+// archiveEntries names frame entries and builds the manifest.
+type archiveEntries struct {
+	opts    ArchiveOptions
+	meta    Metadata
+	entries map[int]string
+}
+
+func newArchiveEntries(opts ArchiveOptions) archiveEntries {
+	if opts.Pattern == "" {
+		opts.Pattern = DefaultArchiveOptions.Pattern
+	}
+	return archiveEntries{opts: opts}
+}
+
+func (a *archiveEntries) open(meta Metadata) {
+	a.meta = meta
+	a.entries = make(map[int]string)
+}
+
+func (a *archiveEntries) name(num int) string {
+	var name string
+	if a.opts.Name != nil {
+		name = a.opts.Name(Frame{Num: num})
+	} else {
+		name = fmt.Sprintf(a.opts.Pattern, a.opts.Start+num)
+	}
+	a.entries[num] = name
+	return name
+}
+
+func (a *archiveEntries) manifest() ([]byte, error) {
+	nums := make([]int, 0, len(a.entries))
+	for num := range a.entries {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	m := ArchiveManifest{
+		Name:       a.meta.Name,
+		Width:      a.meta.Width,
+		Height:     a.meta.Height,
+		FrameRate:  a.meta.FrameRate,
+		FrameCount: len(nums),
+		Frames:     make([]string, len(nums)),
+	}
+	for i, num := range nums {
+		m.Frames[i] = a.entries[num]
+	}
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// ZipSink streams frames into a zip archive as they are rendered.
+// Frames are stored without compression since encoded images hardly
+// compress, the manifest is added on Close.
+//
+// Example:
+//
+//	f, _ := os.Create("frames.zip")
+//	defer f.Close()
+//	err := renderer.RenderAll(golottie.NewZipSink(f, golottie.DefaultArchiveOptions))
+type ZipSink struct {
+	w  io.Writer
+	zw *zip.Writer
+	archiveEntries
+}
+
+// NewZipSink returns zip sink writing to w.
+func NewZipSink(w io.Writer, opts ArchiveOptions) *ZipSink {
+	return &ZipSink{w: w, archiveEntries: newArchiveEntries(opts)}
+}
+
+// Open starts the archive.
+func (s *ZipSink) Open(meta Metadata) error {
+	s.open(meta)
+	s.zw = zip.NewWriter(s.w)
+	return nil
+}
+
+// WriteFrame adds frame to the archive.
+func (s *ZipSink) WriteFrame(f Frame) error {
+	return s.write(s.name(f.Num), f.Data, zip.Store)
+}
+
+func (s *ZipSink) write(name string, data []byte, method uint16) error {
+	w, err := s.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Close adds the manifest and finishes the archive, w is left open.
+func (s *ZipSink) Close() error {
+	if s.opts.Manifest {
+		data, err := s.manifest()
+		if err != nil {
+			return err
+		}
+		if err = s.write(ArchiveManifestName, data, zip.Deflate); err != nil {
+			return err
+		}
+	}
+	return s.zw.Close()
+}
+
+// TarSink streams frames into a tar archive, optionally gzip compressed,
+// as they are rendered. The manifest is added on Close.
+type TarSink struct {
+	w  io.Writer
+	gw *gzip.Writer
+	tw *tar.Writer
+	archiveEntries
+}
+
+// NewTarSink returns tar sink writing to w.
+func NewTarSink(w io.Writer, opts ArchiveOptions) *TarSink {
+	return &TarSink{w: w, archiveEntries: newArchiveEntries(opts)}
+}
+
+// Open starts the archive.
+func (s *TarSink) Open(meta Metadata) error {
+	s.open(meta)
+	w := s.w
+	if s.opts.Gzip {
+		s.gw = gzip.NewWriter(s.w)
+		w = s.gw
+	}
+	s.tw = tar.NewWriter(w)
+	return nil
+}
+
+// WriteFrame adds frame to the archive.
+func (s *TarSink) WriteFrame(f Frame) error {
+	return s.write(s.name(f.Num), f.Data)
+}
+
+func (s *TarSink) write(name string, data []byte) error {
+	if err := s.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := s.tw.Write(data)
+	return err
+}
+
+// Close adds the manifest and finishes the archive, w is left open.
+func (s *TarSink) Close() error {
+	if s.opts.Manifest {
+		data, err := s.manifest()
+		if err != nil {
+			return err
+		}
+		if err = s.write(ArchiveManifestName, data); err != nil {
+			return err
+		}
+	}
+	if err := s.tw.Close(); err != nil {
+		return err
+	}
+	if s.gw != nil {
+		return s.gw.Close()
+	}
+	return nil
+}
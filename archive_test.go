@@ -0,0 +1,79 @@
+package golottie
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var archiveMeta = Metadata{Name: "test", Width: 2, Height: 1, FrameRate: 30, FramesTotal: 2}
+
+func Test_ZipSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewZipSink(&buf, ArchiveOptions{Pattern: "frames/%04d.png", Start: 1, Manifest: true})
+	require.NoError(t, sink.Open(archiveMeta))
+	require.NoError(t, sink.WriteFrame(Frame{Num: 1, Data: []byte{1}}))
+	require.NoError(t, sink.WriteFrame(Frame{Num: 0, Data: []byte{0}}))
+	require.NoError(t, sink.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(r)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, zip.Store, zr.File[0].Method)
+	assert.Equal(t, []byte{1}, files["frames/0002.png"])
+	assert.Equal(t, []byte{0}, files["frames/0001.png"])
+
+	var manifest ArchiveManifest
+	require.NoError(t, json.Unmarshal(files[ArchiveManifestName], &manifest))
+	assert.Equal(t, ArchiveManifest{
+		Name:       "test",
+		Width:      2,
+		Height:     1,
+		FrameRate:  30,
+		FrameCount: 2,
+		Frames:     []string{"frames/0001.png", "frames/0002.png"},
+	}, manifest)
+}
+
+func Test_TarSink(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		var buf bytes.Buffer
+		opts := DefaultArchiveOptions
+		opts.Gzip = compressed
+		sink := NewTarSink(&buf, opts)
+		require.NoError(t, sink.Open(archiveMeta))
+		require.NoError(t, sink.WriteFrame(Frame{Num: 0, Data: []byte{0}}))
+		require.NoError(t, sink.Close())
+
+		r := io.Reader(&buf)
+		if compressed {
+			gr, err := gzip.NewReader(&buf)
+			require.NoError(t, err)
+			r = gr
+		}
+		tr := tar.NewReader(r)
+		var names []string
+		for {
+			h, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			names = append(names, h.Name)
+		}
+		assert.Equal(t, []string{"0000.png", ArchiveManifestName}, names)
+	}
+}
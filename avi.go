@@ -0,0 +1,163 @@
+package golottie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/jpeg"
+	"io"
+	"sort"
+)
+
+// AVI header flags.
+const (
+	aviHasIndex = 0x10
+	aviKeyFrame = 0x10
+)
+
+// AVIOptions configures [AVISink].
+type AVIOptions struct {
+	// Quality in range [1..100] frames are captured with as JPEG.
+	// Zero uses the default quality, greater values are clamped to 100.
+	Quality int
+}
+
+// DefaultAVIOptions captures frames at 90% JPEG quality.
+var DefaultAVIOptions = AVIOptions{Quality: 90}
+
+// AVISink muxes frames captured as JPEG into an MJPEG AVI playable
+// without external encoders. JPEG has no alpha, transparent pixels
+// are captured over the page background. Frames are buffered and the
+// video is written to w on [AVISink.Close] since the headers contain
+// the frame count and sizes.
+type AVISink struct {
+	w      io.Writer
+	opts   AVIOptions
+	meta   Metadata
+	frames []Frame
+}
+
+// NewAVISink returns AVI sink writing to w.
+func NewAVISink(w io.Writer, opts AVIOptions) *AVISink {
+	if opts.Quality <= 0 {
+		opts.Quality = DefaultAVIOptions.Quality
+	} else if opts.Quality > 100 {
+		opts.Quality = 100
+	}
+	return &AVISink{w: w, opts: opts}
+}
+
+// FrameFormat implements [FrameFormatter].
+func (s *AVISink) FrameFormat() (ImageFormat, int) {
+	return FormatJPEG, s.opts.Quality
+}
+
+// Open prepares the sink for the animation.
+func (s *AVISink) Open(meta Metadata) error {
+	s.meta = meta
+	s.frames = s.frames[:0]
+	return nil
+}
+
+// WriteFrame buffers JPEG encoded frame.
+func (s *AVISink) WriteFrame(f Frame) error {
+	if len(s.frames) == 0 {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(f.Data))
+		if err != nil {
+			return fmt.Errorf("error reading frame %d: %w", f.Num, err)
+		}
+		s.meta.Width, s.meta.Height = cfg.Width, cfg.Height
+	}
+	s.frames = append(s.frames, f)
+	return nil
+}
+
+// Close writes the AVI.
+func (s *AVISink) Close() error {
+	if len(s.frames) == 0 {
+		return ErrNoFrames
+	}
+	sort.SliceStable(s.frames, func(i, j int) bool { return s.frames[i].Num < s.frames[j].Num })
+	rate, scale := frameRateRatio(s.meta.FrameRate)
+	maxSize := 0
+	for _, f := range s.frames {
+		if len(f.Data) > maxSize {
+			maxSize = len(f.Data)
+		}
+	}
+	w, h := s.meta.Width, s.meta.Height
+
+	avih := le32(
+		1000000*scale/rate, // microseconds per frame
+		maxSize*rate/scale, // max bytes per second
+		0,                  // padding granularity
+		aviHasIndex,
+		len(s.frames),
+		0, // initial frames
+		1, // streams
+		maxSize,
+		w, h,
+		0, 0, 0, 0,
+	)
+	strh := append([]byte("vidsMJPG"), le32(
+		0, // flags
+		0, // priority and language
+		0, // initial frames
+		scale, rate,
+		0, // start
+		len(s.frames),
+		maxSize,
+		-1, // default quality
+		0,  // sample size
+	)...)
+	strh = append(strh, le16(0, 0, w, h)...)
+	strf := le32(40, w, h)
+	strf = append(strf, le16(1, 24)...)
+	strf = append(strf, "MJPG"...)
+	strf = append(strf, le32(w*h*3, 0, 0, 0, 0)...)
+
+	var strl, hdrl, movi, idx1 bytes.Buffer
+	strl.WriteString("strl")
+	writeRIFFChunk(&strl, "strh", strh)
+	writeRIFFChunk(&strl, "strf", strf)
+	hdrl.WriteString("hdrl")
+	writeRIFFChunk(&hdrl, "avih", avih)
+	writeRIFFChunk(&hdrl, "LIST", strl.Bytes())
+	movi.WriteString("movi")
+	for _, f := range s.frames {
+		// offsets are relative to the "movi" list type
+		idx1.WriteString("00dc")
+		idx1.Write(le32(aviKeyFrame, movi.Len(), len(f.Data)))
+		writeRIFFChunk(&movi, "00dc", f.Data)
+	}
+	s.frames = nil
+
+	var body bytes.Buffer
+	body.WriteString("AVI ")
+	writeRIFFChunk(&body, "LIST", hdrl.Bytes())
+	writeRIFFChunk(&body, "LIST", movi.Bytes())
+	writeRIFFChunk(&body, "idx1", idx1.Bytes())
+	if int64(body.Len()) > 0xffffffff {
+		return fmt.Errorf("error writing avi: %d bytes exceed RIFF size limit", body.Len())
+	}
+	var buf bytes.Buffer
+	writeRIFFChunk(&buf, "RIFF", body.Bytes())
+	_, err := buf.WriteTo(s.w)
+	return err
+}
+
+func le32(values ...int) []byte {
+	var b []byte
+	for _, v := range values {
+		b = binary.LittleEndian.AppendUint32(b, uint32(v))
+	}
+	return b
+}
+
+func le16(values ...int) []byte {
+	var b []byte
+	for _, v := range values {
+		b = binary.LittleEndian.AppendUint16(b, uint16(v))
+	}
+	return b
+}
@@ -0,0 +1,63 @@
+package golottie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func jpegFrame(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)), nil))
+	return buf.Bytes()
+}
+
+func Test_AVISink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewAVISink(&buf, AVIOptions{})
+	format, quality := sink.FrameFormat()
+	assert.Equal(t, FormatJPEG, format)
+	assert.Equal(t, DefaultAVIOptions.Quality, quality)
+	_, quality = NewAVISink(&buf, AVIOptions{Quality: 1}).FrameFormat()
+	assert.Equal(t, 1, quality)
+	_, quality = NewAVISink(&buf, AVIOptions{Quality: 150}).FrameFormat()
+	assert.Equal(t, 100, quality)
+	assert.NoError(t, sink.Open(Metadata{FrameRate: 25}))
+	frames := [][]byte{jpegFrame(t, 6, 4), jpegFrame(t, 6, 4), jpegFrame(t, 6, 4)}
+	for i, f := range frames {
+		assert.NoError(t, sink.WriteFrame(Frame{Num: i, Data: f}))
+	}
+	assert.NoError(t, sink.Close())
+
+	data := buf.Bytes()
+	assert.Equal(t, "RIFF", string(data[:4]))
+	assert.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:]))
+	assert.Equal(t, "AVI ", string(data[8:12]))
+
+	u32 := func(b []byte, i int) int { return int(binary.LittleEndian.Uint32(b[i*4:])) }
+	avih := data[bytes.Index(data, []byte("avih"))+8:]
+	assert.Equal(t, 40000, u32(avih, 0))
+	assert.Equal(t, aviHasIndex, u32(avih, 3))
+	assert.Equal(t, 3, u32(avih, 4))
+	assert.Equal(t, []int{6, 4}, []int{u32(avih, 8), u32(avih, 9)})
+	strh := data[bytes.Index(data, []byte("strh"))+8:]
+	assert.Equal(t, "vidsMJPG", string(strh[:8]))
+	assert.Equal(t, []int{1, 25, 3}, []int{u32(strh, 5), u32(strh, 6), u32(strh, 8)})
+
+	movi := bytes.Index(data, []byte("movi"))
+	idx1 := data[bytes.Index(data, []byte("idx1"))+8:]
+	for i, f := range frames {
+		entry := idx1[i*16:]
+		assert.Equal(t, "00dc", string(entry[:4]))
+		offset, size := u32(entry, 2), u32(entry, 3)
+		assert.Equal(t, len(f), size)
+		assert.Equal(t, "00dc", string(data[movi+offset:movi+offset+4]))
+		assert.Equal(t, f, data[movi+offset+8:movi+offset+8+size])
+	}
+
+	assert.Error(t, NewAVISink(&buf, DefaultAVIOptions).WriteFrame(Frame{Data: []byte("png")}))
+}
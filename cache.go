@@ -0,0 +1,49 @@
+package golottie
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// RenderCache keeps rendered frames in a directory, so re-running an
+// unchanged job doesn't capture the frames again. Entries are named by
+// the hash of their key and are safe to share between processes.
+//
+// Example:
+//
+//	cache, err := golottie.NewRenderCache(".golottie-cache")
+//	...
+//	renderer.SetCache(cache, golottie.HashData(data))
+//	err = renderer.RenderAll(sink)
+type RenderCache struct {
+	dir string
+}
+
+// NewRenderCache returns cache keeping frames in dir, creating it if needed.
+func NewRenderCache(dir string) (*RenderCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &RenderCache{dir: dir}, nil
+}
+
+func (c *RenderCache) path(key string) string {
+	hash := HashData([]byte(key))
+	return filepath.Join(c.dir, hash[:2], hash)
+}
+
+// Get returns data kept under key.
+func (c *RenderCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(c.path(key))
+	return data, err == nil
+}
+
+// Put keeps data under key. Entries are written to a temporary file
+// first, so readers never see partial data.
+func (c *RenderCache) Put(key string, data []byte) error {
+	name := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(name, data)
+}
@@ -0,0 +1,39 @@
+package golottie
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RenderCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewRenderCache(dir)
+	require.NoError(t, err)
+	_, ok := cache.Get("source/options/0")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Put("source/options/0", []byte("frame 0")))
+	require.NoError(t, cache.Put("source/options/1", []byte("frame 1")))
+	data, ok := cache.Get("source/options/0")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("frame 0"), data)
+
+	// entries outlive the cache instance
+	cache, err = NewRenderCache(dir)
+	require.NoError(t, err)
+	data, ok = cache.Get("source/options/1")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("frame 1"), data)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		files, err := os.ReadDir(dir + "/" + entry.Name())
+		require.NoError(t, err)
+		for _, f := range files {
+			assert.NotContains(t, f.Name(), ".tmp-")
+		}
+	}
+}
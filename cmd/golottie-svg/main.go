@@ -6,22 +6,23 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
-	"github.com/galihrivanto/go-inkscape"
 	"github.com/icyrogue/golottie"
 )
 
 const (
 	defTimeout = 1800
-	defWidth   = 1920
-	defHeight  = 1080
+	defWidth   = 0
+	defHeight  = 0
 	defBufSize = 16
 	defWorkers = 1
+	defBackend = "browser"
 )
 
 //gocyclo:ignore
@@ -61,19 +62,42 @@ func run(ctxParent context.Context, logger log.Logger, opts *options) {
 	if err != nil {
 		logger.Fatal(err.Error())
 	}
+	width, height := outputSize(opts, animation.Metadata())
 
 	logger.Info("Allocating frame buffer", "size", opts.bufSize)
 	input := make(chan frame, opts.bufSize)
 	framesTotal := animation.GetFramesTotal()
 	logger.Info("Starting converter", "frames", framesTotal)
+	tmpl, err := golottie.ParseOutputTemplate(opts.output)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	info, err := animation.Info()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	// frame files are numbered from 1
+	sink := golottie.NewDirSinkFunc(golottie.NewFrameNamer(tmpl, info, golottie.OutputVars{
+		Frame:  1,
+		Width:  width,
+		Height: height,
+	}))
+	if err = sink.Open(animation.Metadata()); err != nil {
+		logger.Fatal(err)
+	}
 	var wg sync.WaitGroup
-	conv := newConverter(&wg, input, opts)
+	conv := newConverter(&wg, input, opts, sink, animation.Metadata())
 	for i := 0; i < opts.workers; i++ {
-		go conv.run(ctx, opts.output)
+		r, err := newRasterizer(ctx, opts, width, height)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		wg.Add(1)
+		go conv.run(r)
 	}
 	frame := frame{
-		width:  opts.width,
-		height: opts.height,
+		width:  width,
+		height: height,
 	}
 	for renderer.NextFrame() {
 		var buf string
@@ -90,8 +114,16 @@ func run(ctxParent context.Context, logger log.Logger, opts *options) {
 			log.Fatal(err.Error())
 		}
 	}
-	cancel()
+	// workers drain the frames left before the browser is closed
+	close(input)
 	wg.Wait()
+	cancel()
+	if err = conv.Err(); err != nil {
+		logger.Fatal(err)
+	}
+	if err = sink.Close(); err != nil {
+		logger.Fatal(err)
+	}
 	logger.Info("Done!", "output", path.Dir(opts.output))
 }
 
@@ -99,6 +131,14 @@ type converter struct {
 	wg    *sync.WaitGroup
 	input chan frame
 	opts  *options
+	sink  golottie.Sink
+	meta  golottie.Metadata
+	// svgOpts resolve images relative to the input file
+	svgOpts golottie.SVGOptions
+
+	// mu guards err written by the workers
+	mu  sync.Mutex
+	err error
 }
 
 type frame struct {
@@ -108,71 +148,73 @@ type frame struct {
 	height int
 }
 
-func newConverter(wg *sync.WaitGroup, input chan frame, opts *options) *converter {
+func newConverter(wg *sync.WaitGroup, input chan frame, opts *options, sink golottie.Sink, meta golottie.Metadata) *converter {
 	return &converter{
-		opts:  opts,
-		wg:    wg,
-		input: input,
+		opts:    opts,
+		wg:      wg,
+		input:   input,
+		sink:    sink,
+		meta:    meta,
+		svgOpts: golottie.SVGOptions{Assets: os.DirFS(filepath.Dir(opts.input))},
 	}
 }
 
-//gocyclo:ignore
-func (c *converter) run(ctx golottie.Context, output string) {
-	c.wg.Add(1)
-	proxy := inkscape.NewProxy(inkscape.Verbose(c.opts.verbose))
-	if err := proxy.Run(); err != nil {
-		ctx.Error(err)
+// outputSize returns the size frames are rasterized at, --width and
+// --height default to the animation size as the Inkscape backend uses.
+func outputSize(opts *options, meta golottie.Metadata) (width, height int) {
+	width, height = opts.width, opts.height
+	if width <= 0 {
+		width = meta.Width
 	}
-	defer proxy.Close()
+	if height <= 0 {
+		height = meta.Height
+	}
+	return width, height
+}
+
+// fail records the first error of the workers.
+func (c *converter) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// Err returns the first error the workers ran into.
+func (c *converter) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+//gocyclo:ignore
+func (c *converter) run(r rasterizer) {
 	render := func(v frame) error {
-		f, err := os.CreateTemp(os.TempDir(), fmt.Sprintf(`%d-%d.svg`, time.Now().Unix(), v.num))
-		if err != nil {
-			return err
-		}
-		defer func() {
-			f.Close()
-			os.Remove(f.Name())
-		}()
 		if len(v.buf) == 0 {
 			return nil
 		}
-		_, err = f.WriteString(v.buf)
+		// the frames are rasterized outside of the player page, so
+		// images are embedded first
+		svg, err := golottie.StandaloneSVG(v.buf, c.meta, c.svgOpts)
 		if err != nil {
 			return err
 		}
-		_, err = proxy.RawCommands(
-			"file-open:"+f.Name(),
-			"export-filename:"+fmt.Sprintf(output, v.num),
-			"export-do",
-			"file-close",
-		)
+		buf, err := r.Rasterize(svg)
 		if err != nil {
 			return err
 		}
-		return nil
-	}
-loop:
-	for {
-		select {
-		case <-ctx.Done():
-			break loop
-		case v := <-c.input:
-			fmt.Printf("\r---> Rendering frame %d", v.num)
-			if err := render(v); err != nil {
-				ctx.Error(err)
-			}
-		}
+		return c.sink.WriteFrame(golottie.Frame{Num: v.num - 1, Data: buf})
 	}
-	for {
-		if len(c.input) <= 0 {
-			break
-		}
-		v := <-c.input
+	for v := range c.input {
 		fmt.Printf("\r---> Rendering frame %d", v.num)
 		if err := render(v); err != nil {
-			ctx.Error(err)
+			c.fail(err)
 		}
 	}
+	if err := r.Close(); err != nil {
+		c.fail(err)
+	}
 	fmt.Printf("\r")
 	c.wg.Done()
 }
@@ -184,6 +226,8 @@ type options struct {
 	input  string
 	output string
 
+	backend string
+
 	verbose bool
 	workers int
 	bufSize int
@@ -201,17 +245,19 @@ func parseFlags() *options {
 		workers: defWorkers,
 		timeout: defTimeout,
 		verbose: false,
+		backend: defBackend,
 
 		flagSet: *flag.CommandLine,
 		args:    os.Args[1:],
 	}
 	opts.flagSet.StringVar(&opts.input, "input", "", "input file name")
 	opts.flagSet.StringVar(&opts.input, "i", "", "")
-	opts.flagSet.StringVar(&opts.output, "output", "", "output sprintf pattern")
-	opts.flagSet.StringVar(&opts.output, "o", "", "Ex: render/%04d.png")
-	opts.flagSet.IntVar(&opts.width, "width", defWidth, "width of the output")
+	opts.flagSet.StringVar(&opts.output, "output", "", "output template, see golottie --help")
+	opts.flagSet.StringVar(&opts.output, "o", "", "Ex: render/{frame:04}.png")
+	opts.flagSet.StringVar(&opts.backend, "backend", defBackend, "rasterizer: browser or inkscape (requires Inkscape to be installed)")
+	opts.flagSet.IntVar(&opts.width, "width", defWidth, "width of the output, 0 uses the animation width")
 	opts.flagSet.IntVar(&opts.width, "w", defWidth, "")
-	opts.flagSet.IntVar(&opts.height, "height", defHeight, "height of the output")
+	opts.flagSet.IntVar(&opts.height, "height", defHeight, "height of the output, 0 uses the animation height")
 	opts.flagSet.IntVar(&opts.height, "h", defHeight, "")
 	opts.flagSet.IntVar(&opts.workers, "count", defWorkers, "worker count (goroutines) to be created for concurrent rendering")
 	opts.flagSet.IntVar(&opts.workers, "c", defWorkers, "")
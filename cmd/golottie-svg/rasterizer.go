@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/galihrivanto/go-inkscape"
+	"github.com/icyrogue/golottie"
+)
+
+// rasterizer converts SVG frames into PNG, every worker creates its own.
+type rasterizer interface {
+	Rasterize(svg string) ([]byte, error)
+	Close() error
+}
+
+// newRasterizer creates rasterizer of the backend selected by --backend,
+// the browser renders frames at width x height.
+func newRasterizer(ctx context.Context, opts *options, width, height int) (rasterizer, error) {
+	switch opts.backend {
+	case "browser":
+		r, err := golottie.NewSVGRasterizer(ctx, width, height)
+		if err != nil {
+			return nil, err
+		}
+		return &browserRasterizer{r: r}, nil
+	case "inkscape":
+		proxy := inkscape.NewProxy(inkscape.Verbose(opts.verbose))
+		if err := proxy.Run(); err != nil {
+			return nil, err
+		}
+		return &inkscapeRasterizer{proxy: proxy}, nil
+	}
+	return nil, fmt.Errorf("unknown backend %q, expected browser or inkscape", opts.backend)
+}
+
+// browserRasterizer renders frames in a tab of the headless browser
+// the animation is played in.
+type browserRasterizer struct {
+	r *golottie.SVGRasterizer
+}
+
+func (b *browserRasterizer) Rasterize(svg string) ([]byte, error) {
+	var buf []byte
+	err := b.r.Rasterize(svg, &buf)
+	return buf, err
+}
+
+func (b *browserRasterizer) Close() error {
+	return b.r.Close()
+}
+
+// inkscapeRasterizer exports frames through an Inkscape shell,
+// it requires Inkscape to be installed.
+type inkscapeRasterizer struct {
+	proxy *inkscape.Proxy
+}
+
+func (i *inkscapeRasterizer) Rasterize(svg string) ([]byte, error) {
+	f, err := os.CreateTemp(os.TempDir(), fmt.Sprintf(`%d-*.svg`, time.Now().Unix()))
+	if err != nil {
+		return nil, err
+	}
+	output := strings.TrimSuffix(f.Name(), ".svg") + ".png"
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+		os.Remove(output)
+	}()
+	if _, err = f.WriteString(svg); err != nil {
+		return nil, err
+	}
+	if _, err = i.proxy.RawCommands(
+		"file-open:"+f.Name(),
+		"export-filename:"+output,
+		"export-do",
+		"file-close",
+	); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(output)
+}
+
+func (i *inkscapeRasterizer) Close() error {
+	return i.proxy.Close()
+}
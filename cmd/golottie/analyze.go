@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/icyrogue/golottie"
+)
+
+// runAnalyze prints animation complexity metrics, optionally measuring frame
+// render times in the browser, and exits with a non-zero code if the metrics
+// exceed the budget.
+//
+// Usage: golottie analyze [--profile] [--budget budget.json] [--max-layers 50] animation.json
+func runAnalyze(args []string) {
+	var (
+		opts       options
+		asJSON     bool
+		profile    bool
+		worst      int
+		budgetFile string
+		budget     golottie.Budget
+	)
+	flagSet := flag.NewFlagSet("analyze", flag.ExitOnError)
+	flagSet.StringVar(&opts.input, "input", "", "input file name")
+	flagSet.StringVar(&opts.input, "i", "", "")
+	flagSet.StringVar(&opts.precomp, "precomp", "", "id of the precomp asset to analyze")
+	flagSet.BoolVar(&asJSON, "json", false, "print metrics as JSON")
+	flagSet.BoolVar(&profile, "profile", false, "measure frame render times in the browser")
+	flagSet.IntVar(&worst, "worst", 5, "number of the slowest frames to print")
+	flagSet.StringVar(&budgetFile, "budget", "", "JSON file with budget thresholds")
+	flagSet.IntVar(&budget.MaxLayers, "max-layers", 0, "layer count budget")
+	flagSet.IntVar(&budget.MaxShapes, "max-shapes", 0, "shape count budget")
+	flagSet.IntVar(&budget.MaxVertices, "max-vertices", 0, "path vertex count budget")
+	flagSet.IntVar(&budget.MaxMasks, "max-masks", 0, "mask count budget")
+	flagSet.IntVar(&budget.MaxMattes, "max-mattes", 0, "matte count budget")
+	flagSet.IntVar(&budget.MaxImageBytes, "max-image-bytes", 0, "embedded image bytes budget")
+	flagSet.IntVar(&budget.MaxExpressions, "max-expressions", 0, "expression count budget")
+	flagSet.Float64Var(&budget.MaxFrameTime, "max-frame-time", 0, "frame render time budget in milliseconds, requires --profile")
+	flagSet.Usage = usage(flagSet, "golottie analyze")
+	//nolint:errcheck // flag set exits on error
+	flagSet.Parse(args)
+	if opts.input == "" {
+		opts.input = flagSet.Arg(0)
+	}
+	if opts.input == "" {
+		log.Fatal("--input is not provided, try --help")
+	}
+	if budgetFile != "" {
+		data, err := os.ReadFile(budgetFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Flags override the budget file thresholds
+		var fileBudget golottie.Budget
+		if err = json.Unmarshal(data, &fileBudget); err != nil {
+			log.Fatal(err)
+		}
+		budget = mergeBudget(fileBudget, budget)
+	}
+
+	animation, err := loadAnimation(&opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	complexity, err := animation.Analyze()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if profile {
+		complexity.Frames, err = profileAnimation(animation)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	violations := complexity.Check(budget)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(struct {
+			*golottie.Complexity
+			Violations []golottie.Violation `json:"violations"`
+		}{complexity, violations})
+	} else {
+		err = complexity.WriteText(os.Stdout, worst)
+		for _, v := range violations {
+			fmt.Fprintln(os.Stdout, "budget exceeded:", v)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(violations) > 0 {
+		os.Exit(1)
+	}
+}
+
+// profileAnimation measures every frame render time in the browser.
+func profileAnimation(animation *golottie.AnimationData) ([]golottie.FrameTiming, error) {
+	ctxParent, cancelParent := context.WithTimeout(context.Background(), defTimeout*time.Second)
+	defer cancelParent()
+	ctx, cancel := golottie.NewContext(ctxParent)
+	defer cancel()
+	renderer := golottie.New(ctx)
+	animation, err := animation.WithDefaultTemplate()
+	if err != nil {
+		return nil, err
+	}
+	defer animation.Close()
+	if err = renderer.SetAnimation(animation); err != nil {
+		return nil, err
+	}
+	return renderer.Profile()
+}
+
+// mergeBudget returns base budget with thresholds overridden
+// by non-zero thresholds of override.
+func mergeBudget(base, override golottie.Budget) golottie.Budget {
+	pick := func(b, o int) int {
+		if o != 0 {
+			return o
+		}
+		return b
+	}
+	base.MaxLayers = pick(base.MaxLayers, override.MaxLayers)
+	base.MaxShapes = pick(base.MaxShapes, override.MaxShapes)
+	base.MaxVertices = pick(base.MaxVertices, override.MaxVertices)
+	base.MaxMasks = pick(base.MaxMasks, override.MaxMasks)
+	base.MaxMattes = pick(base.MaxMattes, override.MaxMattes)
+	base.MaxImageBytes = pick(base.MaxImageBytes, override.MaxImageBytes)
+	base.MaxExpressions = pick(base.MaxExpressions, override.MaxExpressions)
+	if override.MaxFrameTime != 0 {
+		base.MaxFrameTime = override.MaxFrameTime
+	}
+	return base
+}
@@ -0,0 +1,61 @@
+package main
+
+import "flag"
+
+// addFlagGroup registers the flags of a format on the command flag set,
+// usage lists them under the title.
+func (opts *options) addFlagGroup(title string, register func(fs *flag.FlagSet)) {
+	group := flag.NewFlagSet(title, flag.ContinueOnError)
+	register(group)
+	group.VisitAll(func(f *flag.Flag) {
+		opts.flagSet.Var(f.Value, f.Name, f.Usage)
+	})
+	opts.groups = append(opts.groups, group)
+}
+
+// encoderFlags registers the flags shared by the animated formats.
+func (opts *options) encoderFlags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.background, "background", "", "#rrggbb color to draw frames over (default: keep transparency, black for y4m)")
+	fs.IntVar(&opts.loop, "loop", 0, "animation loop count, 0 loops forever and -1 plays once")
+	fs.IntVar(&opts.quality, "quality", 0, "webp, avi: frame quality in range 1-100, 100 is lossless webp, 0 uses the format default: 100 for webp, 90 for avi")
+	fs.IntVar(&opts.step, "step", 1, "gif, animated svg: keep every n-th frame")
+}
+
+func (opts *options) gifFlags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.palette, "palette", "global", "palette: global or frame")
+	fs.StringVar(&opts.dither, "dither", "floyd-steinberg", "dithering: floyd-steinberg or none")
+	fs.IntVar(&opts.colors, "colors", 256, "palette size")
+	fs.IntVar(&opts.maxBytes, "max-bytes", 0, "drop frames until the output fits the size, 0 disables the budget")
+}
+
+func (opts *options) apngFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&opts.crop, "crop", true, "store only regions changed since the previous frame")
+}
+
+func (opts *options) spriteFlags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.layout, "layout", "packed", "atlas layout, packed or grid")
+	fs.IntVar(&opts.atlasSize, "atlas-size", 4096, "maximal atlas width and height")
+	fs.IntVar(&opts.padding, "padding", 2, "space between frames in pixels")
+	fs.BoolVar(&opts.trim, "trim", true, "trim transparent frame borders")
+	fs.BoolVar(&opts.dedupe, "dedupe", true, "store identical frames once")
+	fs.BoolVar(&opts.pot, "pot", false, "round atlas dimensions up to powers of two")
+	fs.StringVar(&opts.meta, "meta", "json-hash,css", "comma separated metadata formats: json-hash, json-array, css")
+}
+
+func (opts *options) svgFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&opts.minify, "minify", false, "strip whitespace and round numbers")
+	fs.StringVar(&opts.assets, "assets", "", "directory image paths are relative to (default: input directory)")
+	fs.Var(&opts.fonts, "font", "family=file of the font to embed, can be repeated")
+	fs.StringVar(&opts.svgMode, "svg-mode", "css", "animated svg frame switching, css or smil")
+}
+
+func (opts *options) archiveFlags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.entry, "entry", "{frame:04}.png", "template of frame entry names")
+}
+
+func (opts *options) s3Flags(fs *flag.FlagSet) {
+	fs.StringVar(&opts.s3Endpoint, "s3-endpoint", "", "storage URL such as http://localhost:9000 (default: $AWS_ENDPOINT_URL or AWS)")
+	fs.StringVar(&opts.s3Region, "s3-region", "", "bucket region (default: $AWS_REGION or us-east-1)")
+	fs.IntVar(&opts.s3Concurrency, "s3-concurrency", 4, "uploads running at once")
+	fs.IntVar(&opts.s3PartSize, "s3-part-size", 8, "multipart upload part size in MiB")
+}
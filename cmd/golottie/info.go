@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/charmbracelet/log"
+	"github.com/icyrogue/golottie"
+)
+
+// runInfo prints animation metadata as text or JSON.
+//
+// Usage: golottie info [--json] animation.json
+func runInfo(args []string) {
+	var (
+		input  string
+		asJSON bool
+	)
+	flagSet := flag.NewFlagSet("info", flag.ExitOnError)
+	flagSet.StringVar(&input, "input", "", "input file name")
+	flagSet.StringVar(&input, "i", "", "")
+	flagSet.BoolVar(&asJSON, "json", false, "print metadata as JSON")
+	flagSet.Usage = usage(flagSet, "golottie info")
+	//nolint:errcheck // flag set exits on error
+	flagSet.Parse(args)
+	if input == "" {
+		input = flagSet.Arg(0)
+	}
+	if input == "" {
+		log.Fatal("--input is not provided, try --help")
+	}
+
+	data, err := readInput(input, "", "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	info, err := golottie.NewAnimation(data).Info()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(info)
+	} else {
+		err = info.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"strconv"
@@ -23,18 +24,47 @@ const (
 	defWorkers = 1
 )
 
+// commands contains subcommands, each getting the arguments
+// following the subcommand name.
+var commands = map[string]func(args []string){
+	"analyze":    runAnalyze,
+	"info":       runInfo,
+	"optimize":   runOptimize,
+	"pack":       runPack,
+	"pdf":        runPDF,
+	"storyboard": runStoryboard,
+	"tgs":        runTGS,
+	"thumbnail":  runThumbnail,
+	"validate":   runValidate,
+}
+
 //gocyclo:ignore
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			command(os.Args[2:])
+			return
+		}
+	}
 	opts := parseFlags()
 	err := opts.flagSet.Parse(opts.args)
 	if err != nil {
 		log.Fatal(err)
 	}
-	if opts.input == "" || opts.output == "" {
+	if opts.input == "" || (opts.output == "" && opts.precompOut == "") {
 		log.Fatal("--output or --input is not provided, try --help")
 	}
 
 	logger := newLogger(opts.verbose)
+	if opts.precompOut != "" {
+		if err := writePrecomp(opts); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Precomp saved", "output", opts.precompOut)
+		if opts.output == "" {
+			return
+		}
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(opts.timeout)*time.Second)
 	defer cancel()
 	run(ctx, logger, opts)
@@ -47,11 +77,11 @@ func run(ctxParent context.Context, logger log.Logger, opts *options) {
 	ctx, cancel := golottie.NewContext(ctxParent)
 	renderer := golottie.New(ctx)
 	logger.Info("Parsing animation", "file", opts.input)
-	a, err := os.ReadFile(opts.input)
+	animation, err := loadAnimation(opts)
 	if err != nil {
 		logger.Fatal(err)
 	}
-	animation, err := golottie.NewAnimation(a).WithDefaultTemplate()
+	animation, err = animation.WithDefaultTemplate()
 	if err != nil {
 		logger.Fatal(err.Error())
 	}
@@ -60,43 +90,169 @@ func run(ctxParent context.Context, logger log.Logger, opts *options) {
 		logger.Fatal(err.Error())
 	}
 
+	source, err := os.ReadFile(opts.input)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	sourceHash := golottie.HashData(source)
+	if opts.cache != "" {
+		cache, err := golottie.NewRenderCache(opts.cache)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		renderer.SetCache(cache, sourceHash+"/"+optionsHash(opts))
+	}
+	var done []golottie.ManifestFrame
+	if opts.resume {
+		if done, err = resumeFrames(opts, animation, sourceHash); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Info("Resuming", "frames done", len(done))
+	}
+
+	sink, err := newSink(opts, animation)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	switch sink.(type) {
+	case *golottie.DirSink, *golottie.S3Sink:
+	default:
+		// encoders expect frames in order
+		opts.workers = 1
+	}
+	if opts.manifest != "" {
+		if sink, err = newManifestSink(opts, animation, sink, source, done, renderer.LottieWebVersion()); err != nil {
+			logger.Fatal(err)
+		}
+	}
+	if err = sink.Open(animation.Metadata()); err != nil {
+		logger.Fatal(err)
+	}
+
 	logger.Info("Allocating frame buffer", "size", opts.bufSize)
 	input := make(chan frame, opts.bufSize)
-	framesTotal := animation.GetFramesTotal()
-	logger.Info("Starting converter", "frames", framesTotal)
+	frames := pendingFrames(animation.GetFramesTotal(), done)
+	logger.Info("Starting converter", "frames", len(frames))
 	var wg sync.WaitGroup
-	conv := newConverter(&wg, input, opts)
+	conv := newConverter(&wg, input, opts, sink)
 	for i := 0; i < opts.workers; i++ {
-		go conv.run(ctx, opts.output)
+		wg.Add(1)
+		go conv.run()
 	}
 	frame := frame{
 		width:  opts.width,
 		height: opts.height,
 	}
-	for renderer.NextFrame() {
+	format, quality := golottie.FormatPNG, 0
+	if f, ok := sink.(golottie.FrameFormatter); ok {
+		format, quality = f.FrameFormat()
+	}
+	var svgOpts *golottie.SVGOptions
+	if outputFormat(opts) == "svg" {
+		if svgOpts, err = svgOptions(opts); err != nil {
+			logger.Fatal(err)
+		}
+	}
+	for _, n := range frames {
+		if err = renderer.GoToFrame(n); err != nil {
+			log.Fatal(err.Error())
+		}
 		var buf []byte
-		err := renderer.RenderFrame(&buf)
+		if svgOpts != nil {
+			var svg string
+			err = renderer.RenderFrameStandaloneSVG(&svg, *svgOpts)
+			buf = []byte(svg)
+		} else {
+			err = renderer.RenderFrameAs(&buf, format, quality)
+		}
 		if err != nil {
 			log.Fatal(err.Error())
 		}
-		frame.num++
+		frame.num = n + 1
 		frame.buf = buf
 		input <- frame
 	}
-	if err = ctx.Errors[len(ctx.Errors)-1]; err != nil {
-		if err != golottie.EOF {
-			log.Fatal(err.Error())
-		}
+	if n := len(ctx.Errors); n > 0 && ctx.Errors[n-1] != golottie.EOF {
+		log.Fatal(ctx.Errors[n-1].Error())
 	}
-	cancel()
+	// workers drain the frames left before their errors are checked
+	close(input)
 	wg.Wait()
-	logger.Info("Done!", "output", path.Dir(opts.output))
+	cancel()
+	if err = conv.Err(); err != nil {
+		logger.Fatal(err)
+	}
+	if err = sink.Close(); err != nil {
+		logger.Fatal(err)
+	}
+	if _, ok := sink.(*golottie.DirSink); ok {
+		logger.Info("Done!", "output", path.Dir(opts.output))
+		return
+	}
+	logger.Info("Done!", "output", opts.output)
+}
+
+// loadAnimation reads the input animation and selects the precomp
+// to render if one is provided.
+func loadAnimation(opts *options) (*golottie.AnimationData, error) {
+	a, err := readInput(opts.input, opts.animation, opts.theme)
+	if err != nil {
+		return nil, err
+	}
+	animation := golottie.NewAnimation(a)
+	if opts.precomp != "" {
+		return animation.WithPrecomp(opts.precomp)
+	}
+	return animation, nil
+}
+
+// readInput reads animation data from the input file decompressing
+// Telegram stickers. Animation with the provided id and theme is selected
+// if the input is a dotLottie.
+func readInput(input, animationID, themeID string) ([]byte, error) {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return nil, err
+	}
+	if golottie.IsTGS(data) {
+		return golottie.DecodeTGS(data)
+	}
+	if !golottie.IsDotLottie(data) {
+		return data, nil
+	}
+	container, err := golottie.ReadDotLottie(data)
+	if err != nil {
+		return nil, err
+	}
+	return container.Animation(animationID, themeID)
+}
+
+// writePrecomp saves the selected precomp as a standalone animation.
+func writePrecomp(opts *options) error {
+	if opts.precomp == "" {
+		return fmt.Errorf("--precomp-out requires --precomp to be provided")
+	}
+	a, err := readInput(opts.input, opts.animation, opts.theme)
+	if err != nil {
+		return err
+	}
+	data, err := golottie.ExtractPrecomp(a, opts.precomp)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(opts.precompOut, data, 0o644)
 }
 
 type converter struct {
-	wg    *sync.WaitGroup
-	input chan frame
-	opts  *options
+	wg       *sync.WaitGroup
+	input    chan frame
+	opts     *options
+	sink     golottie.Sink
+	progress io.Writer
+
+	// mu guards err written by the workers
+	mu  sync.Mutex
+	err error
 }
 
 type frame struct {
@@ -106,48 +262,46 @@ type frame struct {
 	height int
 }
 
-func newConverter(wg *sync.WaitGroup, input chan frame, opts *options) *converter {
+func newConverter(wg *sync.WaitGroup, input chan frame, opts *options, sink golottie.Sink) *converter {
+	progress := io.Writer(os.Stdout)
+	if opts.output == "-" {
+		// keep stdout clean for the stream
+		progress = os.Stderr
+	}
 	return &converter{
-		opts:  opts,
-		wg:    wg,
-		input: input,
+		opts:     opts,
+		wg:       wg,
+		input:    input,
+		sink:     sink,
+		progress: progress,
 	}
 }
 
-//gocyclo:ignore
-func (c *converter) run(ctx golottie.Context, output string) {
-	c.wg.Add(1)
-	render := func(v frame) error {
-		err := os.WriteFile(fmt.Sprintf(output, v.num), v.buf, 77777)
-		if err != nil {
-			return err
-		}
-		return nil
-	}
-loop:
-	for {
-		select {
-		case <-ctx.Done():
-			break loop
-		case v := <-c.input:
-			fmt.Printf("\r---> Rendering frame %d", v.num)
-			if err := render(v); err != nil {
-				ctx.Error(err)
-			}
-		}
+// fail records the first error of the workers.
+func (c *converter) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
 	}
-	for {
-		if len(c.input) <= 0 {
-			break
-		}
-		v := <-c.input
-		fmt.Printf("\r---> Rendering frame %d", v.num)
-		if err := render(v); err != nil {
-			ctx.Error(err)
+}
+
+// Err returns the first error the workers ran into writing frames.
+func (c *converter) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *converter) run() {
+	defer c.wg.Done()
+	for v := range c.input {
+		fmt.Fprintf(c.progress, "\r---> Rendering frame %d", v.num)
+		if err := c.sink.WriteFrame(golottie.Frame{Num: v.num - 1, Data: v.buf}); err != nil {
+			c.fail(err)
 		}
 	}
-	fmt.Printf("\r")
-	c.wg.Done()
+	fmt.Fprintf(c.progress, "\r")
 }
 
 type options struct {
@@ -157,13 +311,51 @@ type options struct {
 	input  string
 	output string
 
+	animation  string
+	theme      string
+	precomp    string
+	precompOut string
+
+	format     string
+	palette    string
+	dither     string
+	colors     int
+	background string
+	loop       int
+	step       int
+	maxBytes   int
+	crop       bool
+	quality    int
+	layout     string
+	atlasSize  int
+	padding    int
+	trim       bool
+	dedupe     bool
+	pot        bool
+	meta       string
+	minify     bool
+	assets     string
+	fonts      fontFlags
+	svgMode    string
+	entry      string
+	manifest   string
+	resume     bool
+	cache      string
+
+	s3Endpoint    string
+	s3Region      string
+	s3Concurrency int
+	s3PartSize    int
+
 	verbose bool
 	workers int
 	bufSize int
 	timeout int
 
 	flagSet flag.FlagSet
-	args    []string
+	// groups are the flags of the output formats
+	groups []*flag.FlagSet
+	args   []string
 }
 
 func parseFlags() *options {
@@ -180,8 +372,16 @@ func parseFlags() *options {
 	}
 	opts.flagSet.StringVar(&opts.input, "input", "", "input file name")
 	opts.flagSet.StringVar(&opts.input, "i", "", "")
-	opts.flagSet.StringVar(&opts.output, "output", "", "output sprintf pattern")
-	opts.flagSet.StringVar(&opts.output, "o", "", "Ex: render/%04d.png")
+	opts.flagSet.StringVar(&opts.output, "output", "", "output template such as render/{frame:04}.png, s3://bucket/key or - for stdout")
+	opts.flagSet.StringVar(&opts.output, "o", "", "Ex: render/{frame:04}.png")
+	opts.flagSet.StringVar(&opts.animation, "animation", "", "id of the dotLottie animation to render")
+	opts.flagSet.StringVar(&opts.theme, "theme", "", "id of the dotLottie theme to apply")
+	opts.flagSet.StringVar(&opts.precomp, "precomp", "", "id of the precomp asset to render as a standalone animation")
+	opts.flagSet.StringVar(&opts.precompOut, "precomp-out", "", "file to save the standalone precomp animation JSON to")
+	opts.flagSet.StringVar(&opts.format, "format", "", "output format: gif, apng, webp, avi, y4m, rgba, sprite, svg, animated-svg, zip, tar, tar.gz, stream or a registered sink (default: detected from the output extension, image sequence otherwise)")
+	opts.flagSet.StringVar(&opts.manifest, "manifest", "", "file or s3://bucket/key to write the JSON manifest describing the frames to")
+	opts.flagSet.BoolVar(&opts.resume, "resume", false, "render only the frames missing from the --manifest or changed since, or the frames whose files don't exist without --manifest")
+	opts.flagSet.StringVar(&opts.cache, "cache", "", "directory to keep captured frames in, so unchanged frames aren't captured again")
 	opts.flagSet.IntVar(&opts.width, "width", defWidth, "width of the output")
 	opts.flagSet.IntVar(&opts.width, "w", defWidth, "")
 	opts.flagSet.IntVar(&opts.height, "height", defHeight, "height of the output")
@@ -192,6 +392,13 @@ func parseFlags() *options {
 	opts.flagSet.IntVar(&opts.bufSize, "b", defBufSize, "short for --bufsize")
 	opts.flagSet.BoolVar(&opts.verbose, "verbose", true, "should I have a mouth to scream?")
 	opts.flagSet.BoolVar(&opts.verbose, "q", false, "")
+	opts.addFlagGroup("Animation options", opts.encoderFlags)
+	opts.addFlagGroup("GIF options", opts.gifFlags)
+	opts.addFlagGroup("APNG options", opts.apngFlags)
+	opts.addFlagGroup("Sprite sheet options", opts.spriteFlags)
+	opts.addFlagGroup("SVG options", opts.svgFlags)
+	opts.addFlagGroup("Archive options", opts.archiveFlags)
+	opts.addFlagGroup("S3 options", opts.s3Flags)
 
 	if t, err := strconv.Atoi(os.Getenv("GOLOTTIE_TIMEOUT")); err != nil && opts.verbose {
 		log.Warn("setting timeout value to default:", err)
@@ -199,23 +406,43 @@ func parseFlags() *options {
 		opts.timeout = t
 	}
 	log.Warn(opts.timeout)
-	opts.flagSet.Usage = func() {
-		fmt.Fprint(opts.flagSet.Output(), "Usage of golottie:\n\n")
+	opts.flagSet.Usage = usage(&opts.flagSet, "golottie", opts.groups...)
+
+	return &opts
+}
+
+// usage returns a usage function printing short and long flags together,
+// flags of the groups are listed after the rest under the group names.
+func usage(flagSet *flag.FlagSet, name string, groups ...*flag.FlagSet) func() {
+	return func() {
+		fmt.Fprintf(flagSet.Output(), "Usage of %s:\n\n", name)
+		grouped := make(map[string]bool)
+		for _, group := range groups {
+			group.VisitAll(func(f *flag.Flag) { grouped[f.Name] = true })
+		}
 		var b strings.Builder
-		opts.flagSet.VisitAll(func(f *flag.Flag) {
-			if len(f.Name) == 1 {
-				fmt.Fprintf(&b, "-%s ", f.Name)
-				return
-			}
-			fmt.Fprintf(&b, "--%s\t%s\n", f.Name, f.Usage)
-			if f.DefValue != "" {
-				fmt.Fprintf(&b, "\t\t(default: %s)\n", f.DefValue)
+		flagSet.VisitAll(func(f *flag.Flag) {
+			if !grouped[f.Name] {
+				writeFlagUsage(&b, f)
 			}
 		})
-		fmt.Fprint(opts.flagSet.Output(), b.String())
+		for _, group := range groups {
+			fmt.Fprintf(&b, "\n%s:\n\n", group.Name())
+			group.VisitAll(func(f *flag.Flag) { writeFlagUsage(&b, f) })
+		}
+		fmt.Fprint(flagSet.Output(), b.String())
 	}
+}
 
-	return &opts
+func writeFlagUsage(b *strings.Builder, f *flag.Flag) {
+	if len(f.Name) == 1 {
+		fmt.Fprintf(b, "-%s ", f.Name)
+		return
+	}
+	fmt.Fprintf(b, "--%s\t%s\n", f.Name, f.Usage)
+	if f.DefValue != "" {
+		fmt.Fprintf(b, "\t\t(default: %s)\n", f.DefValue)
+	}
 }
 
 func newLogger(verbose bool) log.Logger {
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/icyrogue/golottie"
+)
+
+// runOptimize writes minified animation and reports size savings,
+// optionally verifying that the optimized animation renders the same.
+//
+// Usage: golottie optimize [--verify] -i animation.json -o animation.min.json
+func runOptimize(args []string) {
+	var (
+		input, output string
+		verify        bool
+		step          int
+		threshold     uint
+		tolerance     float64
+
+		keepHidden, keepAssets, keepKeyframes, keepEditor bool
+	)
+	opts := golottie.DefaultOptimizeOptions
+	flagSet := flag.NewFlagSet("optimize", flag.ExitOnError)
+	flagSet.StringVar(&input, "input", "", "input file name")
+	flagSet.StringVar(&input, "i", "", "")
+	flagSet.StringVar(&output, "output", "", "output file name")
+	flagSet.StringVar(&output, "o", "", "")
+	flagSet.IntVar(&opts.Precision, "precision", opts.Precision, "decimal places to round numbers to, -1 to disable")
+	flagSet.BoolVar(&keepHidden, "keep-hidden", false, "keep hidden layers")
+	flagSet.BoolVar(&keepAssets, "keep-assets", false, "keep unused assets")
+	flagSet.BoolVar(&keepKeyframes, "keep-keyframes", false, "keep static keyframes")
+	flagSet.BoolVar(&keepEditor, "keep-editor", false, "keep editor only fields")
+	flagSet.BoolVar(&verify, "verify", false, "render both animations and compare the frames")
+	flagSet.IntVar(&step, "step", 1, "compare every n-th frame when verifying")
+	flagSet.UintVar(&threshold, "threshold", 2, "per channel difference (0-255) for pixels to be considered changed")
+	flagSet.Float64Var(&tolerance, "tolerance", 0.001, "allowed fraction of changed pixels per frame")
+	flagSet.Usage = usage(flagSet, "golottie optimize")
+	//nolint:errcheck // flag set exits on error
+	flagSet.Parse(args)
+	if input == "" || output == "" {
+		log.Fatal("--output or --input is not provided, try --help")
+	}
+	opts.RemoveHiddenLayers = !keepHidden
+	opts.RemoveUnusedAssets = !keepAssets
+	opts.CollapseStaticKeyframes = !keepKeyframes
+	opts.StripEditorFields = !keepEditor
+
+	data, err := readInput(input, "", "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	optimized, stats, err := golottie.Optimize(data, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(stats)
+	if verify {
+		diffs, err := verifyOptimized(data, optimized, step, uint8(threshold), tolerance)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, d := range diffs {
+			fmt.Printf("frame %d differs: %.3f%% pixels changed\n", d.Frame, d.Changed*100)
+		}
+		if len(diffs) > 0 {
+			log.Fatal("optimized animation doesn't match the original, output is not written")
+		}
+		fmt.Println("frames match")
+	}
+	if err = os.WriteFile(output, optimized, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// verifyOptimized renders both animations and returns frames differing
+// by more than tolerance.
+func verifyOptimized(data, optimized []byte, step int, threshold uint8, tolerance float64) ([]golottie.FrameDiff, error) {
+	ctxParent, cancelParent := context.WithTimeout(context.Background(), defTimeout*time.Second)
+	defer cancelParent()
+	ctx, cancel := golottie.NewContext(ctxParent)
+	defer cancel()
+	before, err := golottie.NewAnimation(data).WithDefaultTemplate()
+	if err != nil {
+		return nil, err
+	}
+	defer before.Close()
+	after, err := golottie.NewAnimation(optimized).WithDefaultTemplate()
+	if err != nil {
+		return nil, err
+	}
+	defer after.Close()
+	return golottie.New(ctx).CompareRender(before, after, step, threshold, tolerance)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/icyrogue/golottie"
+)
+
+// runPack writes a dotLottie container from the animation JSON and
+// the directory containing its images and themes.
+//
+// Usage: golottie pack [--assets dir] [--id name] -i animation.json -o animation.lottie
+func runPack(args []string) {
+	var input, output, assets, id string
+	flagSet := flag.NewFlagSet("pack", flag.ExitOnError)
+	flagSet.StringVar(&input, "input", "", "input animation JSON file name")
+	flagSet.StringVar(&input, "i", "", "")
+	flagSet.StringVar(&output, "output", "", "output dotLottie file name")
+	flagSet.StringVar(&output, "o", "", "")
+	flagSet.StringVar(&assets, "assets", "", "directory image paths are relative to (default: input directory)")
+	flagSet.StringVar(&id, "id", "", "animation id (default: input file name)")
+	flagSet.Usage = usage(flagSet, "golottie pack")
+	//nolint:errcheck // flag set exits on error
+	flagSet.Parse(args)
+	if input == "" || output == "" {
+		log.Fatal("--output or --input is not provided, try --help")
+	}
+	if assets == "" {
+		assets = filepath.Dir(input)
+	}
+	if id == "" {
+		id = strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	}
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the container is written once it's complete, so a failed pack
+	// doesn't leave a truncated file behind
+	var buf bytes.Buffer
+	if err = golottie.WriteDotLottie(&buf, id, data, os.DirFS(assets)); err != nil {
+		log.Fatal(err)
+	}
+	f, err := golottie.CreateAtomic(output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err = buf.WriteTo(f); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/icyrogue/golottie"
+)
+
+// runPDF prints the selected frames as vector PDF pages, either
+// combined into one document or into a file per frame.
+//
+// Usage: golottie pdf [--frames 0,10,20-30] [--split] -i animation.json -o animation.pdf
+func runPDF(args []string) {
+	var (
+		input, output string
+		animationID   string
+		themeID       string
+		frames        string
+		split         bool
+	)
+	flagSet := flag.NewFlagSet("pdf", flag.ExitOnError)
+	flagSet.StringVar(&input, "input", "", "input file name")
+	flagSet.StringVar(&input, "i", "", "")
+	flagSet.StringVar(&output, "output", "", "output PDF file name, output template with --split. Ex: pdf/{frame:04}.pdf")
+	flagSet.StringVar(&output, "o", "", "")
+	flagSet.StringVar(&animationID, "animation", "", "id of the dotLottie animation to render")
+	flagSet.StringVar(&themeID, "theme", "", "id of the dotLottie theme to apply")
+	flagSet.StringVar(&frames, "frames", "0", "comma separated frames and frame ranges. Ex: 0,10,20-30")
+	flagSet.BoolVar(&split, "split", false, "write every frame into its own PDF")
+	flagSet.Usage = usage(flagSet, "golottie pdf")
+	//nolint:errcheck // flag set exits on error
+	flagSet.Parse(args)
+	if input == "" {
+		input = flagSet.Arg(0)
+	}
+	if input == "" || output == "" {
+		log.Fatal("--output or --input is not provided, try --help")
+	}
+	selected, err := parseFrames(frames)
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := readInput(input, animationID, themeID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctxParent, cancelParent := context.WithTimeout(context.Background(), defTimeout*time.Second)
+	defer cancelParent()
+	ctx, cancel := golottie.NewContext(ctxParent)
+	defer cancel()
+	animation, err := golottie.NewAnimation(data).WithDefaultTemplate()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer animation.Close()
+	renderer := golottie.New(ctx)
+	if err = renderer.SetAnimation(animation); err != nil {
+		log.Fatal(err)
+	}
+
+	if !split {
+		pdf, err := renderer.RenderPDF(selected)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err = os.WriteFile(output, pdf, 0o644); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	// files are named after the animation frames
+	name, err := parseFrameNamer(output, animation, golottie.OutputVars{
+		Width:  animation.GetWidth(),
+		Height: animation.GetHeight(),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	sink := golottie.NewDirSinkFunc(name)
+	if err = sink.Open(animation.Metadata()); err != nil {
+		log.Fatal(err)
+	}
+	for _, frame := range selected {
+		var pdf []byte
+		if err = renderer.GoToFrame(frame); err != nil {
+			log.Fatal(err)
+		}
+		if err = renderer.RenderFramePDF(&pdf); err != nil {
+			log.Fatal(err)
+		}
+		if err = sink.WriteFrame(golottie.Frame{Num: frame, Data: pdf}); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err = sink.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseFrames parses comma separated frames and inclusive frame ranges.
+func parseFrames(s string) ([]int, error) {
+	var frames []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid frame %q", part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(to); err != nil || end < start {
+				return nil, fmt.Errorf("invalid frame range %q", part)
+			}
+		}
+		for frame := start; frame <= end; frame++ {
+			frames = append(frames, frame)
+		}
+	}
+	return frames, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/icyrogue/golottie"
+)
+
+// manifestCheckpoint is the number of frames after which the manifest
+// is rewritten, so an interrupted render can be resumed.
+const manifestCheckpoint = 50
+
+// renderOptions are the options affecting rendered frames, hashed to
+// tell whether frames of a previous render can be reused.
+type renderOptions struct {
+	Animation  string    `json:"animation,omitempty"`
+	Theme      string    `json:"theme,omitempty"`
+	Precomp    string    `json:"precomp,omitempty"`
+	Format     string    `json:"format"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	Quality    int       `json:"quality,omitempty"`
+	Background string    `json:"background,omitempty"`
+	Minify     bool      `json:"minify,omitempty"`
+	Assets     string    `json:"assets,omitempty"`
+	Fonts      fontFlags `json:"fonts,omitempty"`
+}
+
+// optionsHash returns the hash of the options affecting rendered frames.
+func optionsHash(opts *options) string {
+	data, _ := json.Marshal(renderOptions{
+		Animation:  opts.animation,
+		Theme:      opts.theme,
+		Precomp:    opts.precomp,
+		Format:     outputFormat(opts),
+		Width:      opts.width,
+		Height:     opts.height,
+		Quality:    opts.quality,
+		Background: opts.background,
+		Minify:     opts.minify,
+		Assets:     opts.assets,
+		Fonts:      opts.fonts,
+	})
+	return golottie.HashData(data)
+}
+
+// resumeFrames returns the frames of the previous render listed in the
+// manifest which are still intact. Without --manifest the frames whose
+// files exist are kept as is, they are complete since frames are renamed
+// into place once written. Nothing is resumed if the manifest doesn't
+// exist yet.
+func resumeFrames(opts *options, animation *golottie.AnimationData, sourceHash string) ([]golottie.ManifestFrame, error) {
+	if format := outputFormat(opts); (format != "" && format != "svg") || isS3(opts.output) || isS3(opts.manifest) {
+		return nil, fmt.Errorf("--resume is supported for local image sequences only")
+	}
+	if opts.manifest == "" {
+		return existingFrames(opts, animation)
+	}
+	f, err := os.Open(opts.manifest)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := golottie.ReadRenderManifest(f)
+	if err != nil {
+		return nil, err
+	}
+	return m.VerifyFrames(sourceHash, optionsHash(opts), os.ReadFile), nil
+}
+
+// existingFrames returns the frames whose output files exist. Unlike the
+// manifest they can't tell if the source or the options changed.
+func existingFrames(opts *options, animation *golottie.AnimationData) ([]golottie.ManifestFrame, error) {
+	name, err := frameNamer(opts, animation, opts.output)
+	if err != nil {
+		return nil, err
+	}
+	var done []golottie.ManifestFrame
+	for n := 0; n < animation.GetFramesTotal(); n++ {
+		file := name(golottie.Frame{Num: n})
+		if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() && info.Size() > 0 {
+			done = append(done, golottie.ManifestFrame{Frame: n, File: file})
+		}
+	}
+	return done, nil
+}
+
+// pendingFrames returns numbers of the frames which aren't done.
+func pendingFrames(framesTotal int, done []golottie.ManifestFrame) []int {
+	skip := make(map[int]bool, len(done))
+	for _, frame := range done {
+		skip[frame.Frame] = true
+	}
+	frames := make([]int, 0, framesTotal)
+	for n := 0; n < framesTotal; n++ {
+		if !skip[n] {
+			frames = append(frames, n)
+		}
+	}
+	return frames
+}
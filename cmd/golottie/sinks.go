@@ -0,0 +1,416 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/icyrogue/golottie"
+)
+
+// outputFormat returns --format or the format implied by the output
+// extension, empty string means an image sequence.
+func outputFormat(opts *options) string {
+	if opts.format != "" {
+		return strings.ToLower(opts.format)
+	}
+	lower := strings.ToLower(opts.output)
+	if strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") {
+		return "tar.gz"
+	}
+	switch ext := strings.ToLower(filepath.Ext(opts.output)); ext {
+	case ".gif", ".apng", ".webp", ".y4m", ".avi":
+		return ext[1:]
+	case ".rgba", ".raw":
+		return "rgba"
+	case ".zip", ".tar":
+		return ext[1:]
+	case ".svg":
+		if !isSequence(opts.output) {
+			return "animated-svg"
+		}
+		return "svg"
+	case ".png":
+		// a single file name without a frame number variable
+		if !isSequence(opts.output) {
+			return "apng"
+		}
+	}
+	return ""
+}
+
+// isSequence reports whether output is a template naming every frame.
+func isSequence(output string) bool {
+	return strings.ContainsAny(output, "%{")
+}
+
+// newSink creates the sink for the output format. Image and SVG sequences
+// are written to a directory or uploaded to s3://bucket/pattern, formats
+// without options specific to the CLI are looked up in the registered sinks.
+func newSink(opts *options, animation *golottie.AnimationData) (golottie.Sink, error) {
+	format := outputFormat(opts)
+	if q := opts.quality; (format == "webp" || format == "avi") && (q < 0 || q > 100) {
+		return nil, fmt.Errorf("quality %d is out of range 1-100", q)
+	}
+	var newFormatSink func(w io.Writer) (golottie.Sink, error)
+	switch format {
+	case "", "svg":
+		if opts.output == "-" {
+			return nil, fmt.Errorf("--format is required to write to stdout")
+		}
+		if !isS3(opts.output) {
+			name, err := frameNamer(opts, animation, opts.output)
+			if err != nil {
+				return nil, err
+			}
+			return golottie.NewDirSinkFunc(name), nil
+		}
+		cfg, key, err := s3Config(opts, opts.output)
+		if err != nil {
+			return nil, err
+		}
+		name, err := frameNamer(opts, animation, key)
+		if err != nil {
+			return nil, err
+		}
+		return golottie.NewS3SinkFunc(cfg, name), nil
+	case "sprite":
+		return spriteSheetSink(opts)
+	case "zip", "tar", "tar.gz":
+		entry, err := frameNamer(opts, animation, opts.entry)
+		if err != nil {
+			return nil, err
+		}
+		archiveOpts := golottie.ArchiveOptions{Name: entry, Manifest: true, Gzip: format == "tar.gz"}
+		newFormatSink = func(w io.Writer) (golottie.Sink, error) {
+			if format == "zip" {
+				return golottie.NewZipSink(w, archiveOpts), nil
+			}
+			return golottie.NewTarSink(w, archiveOpts), nil
+		}
+	case "gif":
+		newFormatSink = func(w io.Writer) (golottie.Sink, error) {
+			gifOpts, err := gifOptions(opts)
+			if err != nil {
+				return nil, err
+			}
+			return golottie.NewGIFSink(w, gifOpts), nil
+		}
+	case "apng":
+		newFormatSink = func(w io.Writer) (golottie.Sink, error) {
+			return golottie.NewAPNGSink(w, golottie.APNGOptions{
+				LoopCount: opts.loop,
+				Crop:      opts.crop,
+			}), nil
+		}
+	case "webp":
+		newFormatSink = func(w io.Writer) (golottie.Sink, error) {
+			return golottie.NewWebPSink(w, golottie.WebPOptions{
+				Quality:   opts.quality,
+				LoopCount: opts.loop,
+			}), nil
+		}
+	case "avi":
+		newFormatSink = func(w io.Writer) (golottie.Sink, error) {
+			return golottie.NewAVISink(w, golottie.AVIOptions{Quality: opts.quality}), nil
+		}
+	case "animated-svg":
+		svgOpts, err := svgOptions(opts)
+		if err != nil {
+			return nil, err
+		}
+		animOpts := golottie.AnimatedSVGOptions{
+			LoopCount: opts.loop,
+			Step:      opts.step,
+			Assets:    svgOpts.Assets,
+			Fonts:     svgOpts.Fonts,
+			Minify:    svgOpts.Minify,
+		}
+		switch opts.svgMode {
+		case "css":
+			animOpts.Mode = golottie.AnimatedSVGCSS
+		case "smil":
+			animOpts.Mode = golottie.AnimatedSVGSMIL
+		default:
+			return nil, fmt.Errorf("unknown svg mode %q, expected css or smil", opts.svgMode)
+		}
+		newFormatSink = func(w io.Writer) (golottie.Sink, error) {
+			return golottie.NewAnimatedSVGSink(w, animOpts), nil
+		}
+	case "y4m", "rgba":
+		background, err := parseColor(opts.background)
+		if err != nil {
+			return nil, err
+		}
+		newFormatSink = func(w io.Writer) (golottie.Sink, error) {
+			if format == "y4m" {
+				return golottie.NewY4MSink(w, background), nil
+			}
+			return golottie.NewRawSink(w, background), nil
+		}
+	default:
+		return golottie.NewSink(format, opts.output)
+	}
+	if opts.output == "-" {
+		sink, err := newFormatSink(os.Stdout)
+		return sink, err
+	}
+	f, err := createOutput(opts, opts.output)
+	if err != nil {
+		return nil, err
+	}
+	sink, err := newFormatSink(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return golottie.NewFileSink(sink, f), nil
+}
+
+// frameNamer parses the output template naming every frame, frames are
+// numbered from 1. The renderer captures frames at the animation size.
+func frameNamer(opts *options, animation *golottie.AnimationData, output string) (golottie.FrameNamer, error) {
+	return parseFrameNamer(output, animation, golottie.OutputVars{
+		Frame:  1,
+		Layer:  opts.precomp,
+		Width:  animation.GetWidth(),
+		Height: animation.GetHeight(),
+	})
+}
+
+// parseFrameNamer parses the output template naming every frame of the
+// animation with vars.
+func parseFrameNamer(output string, animation *golottie.AnimationData, vars golottie.OutputVars) (golottie.FrameNamer, error) {
+	tmpl, err := golottie.ParseOutputTemplate(output)
+	if err != nil {
+		return nil, err
+	}
+	if !tmpl.PerFrame() {
+		return nil, fmt.Errorf("output template %q names every frame the same, add {frame}", output)
+	}
+	info, err := animation.Info()
+	if err != nil {
+		return nil, err
+	}
+	return golottie.NewFrameNamer(tmpl, info, vars), nil
+}
+
+// newManifestSink wraps sink to write the manifest describing the frames
+// named as the image sequence or the archive entries, done frames are
+// kept from the previous render. lottieWeb is the version the page reports.
+func newManifestSink(opts *options, animation *golottie.AnimationData, sink golottie.Sink,
+	source []byte, done []golottie.ManifestFrame, lottieWeb string,
+) (golottie.Sink, error) {
+	info, err := animation.Info()
+	if err != nil {
+		return nil, err
+	}
+	// the size is taken from the animation the frames are captured at
+	manifestOpts := golottie.ManifestOptions{
+		Source:      filepath.Base(opts.input),
+		SourceData:  source,
+		OptionsHash: optionsHash(opts),
+		Info:        info,
+		Frames:      done,
+		LottieWeb:   lottieWeb,
+		Checkpoint:  manifestCheckpoint,
+	}
+	switch outputFormat(opts) {
+	case "", "svg":
+		output := opts.output
+		if isS3(output) {
+			if _, output, err = golottie.ParseS3URL(output); err != nil {
+				return nil, err
+			}
+		}
+		manifestOpts.Name, err = frameNamer(opts, animation, output)
+	case "zip", "tar", "tar.gz":
+		manifestOpts.Name, err = frameNamer(opts, animation, opts.entry)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return golottie.NewManifestSink(sink, func() (io.WriteCloser, error) {
+		if isS3(opts.manifest) {
+			return createOutput(opts, opts.manifest)
+		}
+		if err := os.MkdirAll(filepath.Dir(opts.manifest), 0o755); err != nil {
+			return nil, err
+		}
+		// checkpoints replace the manifest, so an interrupted write
+		// keeps the previous one to resume from
+		return golottie.CreateAtomic(opts.manifest)
+	}, manifestOpts), nil
+}
+
+func isS3(output string) bool {
+	return strings.HasPrefix(output, "s3://")
+}
+
+// s3Config returns storage config of s3://bucket/key output and the key,
+// credentials are taken from the environment.
+func s3Config(opts *options, output string) (golottie.S3Config, string, error) {
+	bucket, key, err := golottie.ParseS3URL(output)
+	if err != nil {
+		return golottie.S3Config{}, "", err
+	}
+	cfg := golottie.S3ConfigFromEnv()
+	cfg.Bucket = bucket
+	if opts.s3Endpoint != "" {
+		cfg.Endpoint, cfg.PathStyle = opts.s3Endpoint, true
+	}
+	if opts.s3Region != "" {
+		cfg.Region = opts.s3Region
+	}
+	cfg.Concurrency = opts.s3Concurrency
+	cfg.PartSize = opts.s3PartSize << 20
+	return cfg, key, nil
+}
+
+// createOutput creates the output file or the object uploaded to
+// s3://bucket/key as it's written.
+func createOutput(opts *options, output string) (io.WriteCloser, error) {
+	if isS3(output) {
+		cfg, key, err := s3Config(opts, output)
+		if err != nil {
+			return nil, err
+		}
+		return golottie.NewS3Writer(cfg, key), nil
+	}
+	return os.Create(output)
+}
+
+func gifOptions(opts *options) (golottie.GIFOptions, error) {
+	gifOpts := golottie.DefaultGIFOptions
+	switch opts.palette {
+	case "global":
+		gifOpts.Palette = golottie.GIFPaletteGlobal
+	case "frame":
+		gifOpts.Palette = golottie.GIFPalettePerFrame
+	default:
+		return gifOpts, fmt.Errorf("unknown palette %q, expected global or frame", opts.palette)
+	}
+	switch opts.dither {
+	case "floyd-steinberg":
+		gifOpts.Dither = golottie.GIFDitherFloydSteinberg
+	case "none":
+		gifOpts.Dither = golottie.GIFDitherNone
+	default:
+		return gifOpts, fmt.Errorf("unknown dither %q, expected floyd-steinberg or none", opts.dither)
+	}
+	background, err := parseColor(opts.background)
+	if err != nil {
+		return gifOpts, err
+	}
+	gifOpts.Colors = opts.colors
+	gifOpts.Transparent = opts.background == ""
+	if background != nil {
+		gifOpts.Background = background
+	}
+	gifOpts.LoopCount = opts.loop
+	gifOpts.Step = opts.step
+	gifOpts.MaxBytes = opts.maxBytes
+	return gifOpts, nil
+}
+
+// svgOptions returns options of the standalone SVG frames.
+func svgOptions(opts *options) (*golottie.SVGOptions, error) {
+	assets := opts.assets
+	if assets == "" {
+		assets = filepath.Dir(opts.input)
+	}
+	svgOpts := &golottie.SVGOptions{
+		Assets: os.DirFS(assets),
+		Minify: opts.minify,
+		Fonts:  make(map[string][]byte, len(opts.fonts)),
+	}
+	for family, file := range opts.fonts {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		svgOpts.Fonts[family] = data
+	}
+	return svgOpts, nil
+}
+
+// fontFlags collects repeated family=file flags.
+type fontFlags map[string]string
+
+func (f *fontFlags) String() string {
+	pairs := make([]string, 0, len(*f))
+	for family, file := range *f {
+		pairs = append(pairs, family+"="+file)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f *fontFlags) Set(s string) error {
+	family, file, ok := strings.Cut(s, "=")
+	if !ok || family == "" || file == "" {
+		return fmt.Errorf("invalid font %q, expected family=file", s)
+	}
+	if *f == nil {
+		*f = make(fontFlags)
+	}
+	(*f)[family] = file
+	return nil
+}
+
+// parseColor parses #rgb or #rrggbb color, empty string yields nil.
+func parseColor(s string) (color.Color, error) {
+	if s == "" {
+		return nil, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return nil, fmt.Errorf("invalid color %q, expected #rrggbb", s)
+	}
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+// spriteSheetSink creates sprite sheet files next to the output named
+// after its base name.
+func spriteSheetSink(opts *options) (golottie.Sink, error) {
+	spriteOpts := golottie.SpriteSheetOptions{
+		MaxSize:    opts.atlasSize,
+		Padding:    opts.padding,
+		Trim:       opts.trim,
+		Dedupe:     opts.dedupe,
+		PowerOfTwo: opts.pot,
+	}
+	switch opts.layout {
+	case "packed":
+		spriteOpts.Layout = golottie.SpritePacked
+	case "grid":
+		spriteOpts.Layout = golottie.SpriteGrid
+	default:
+		return nil, fmt.Errorf("unknown layout %q, expected packed or grid", opts.layout)
+	}
+	for _, format := range strings.Split(opts.meta, ",") {
+		spriteOpts.Formats = append(spriteOpts.Formats, golottie.SpriteFormat(strings.TrimSpace(format)))
+	}
+	dir, join := filepath.Dir(opts.output), filepath.Join
+	if isS3(opts.output) {
+		// path functions would clean the scheme slashes
+		dir = opts.output[:strings.LastIndexByte(opts.output, '/')]
+		join = func(elem ...string) string { return strings.Join(elem, "/") }
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(opts.output), filepath.Ext(opts.output))
+	create := func(name string) (io.WriteCloser, error) {
+		return createOutput(opts, join(dir, name))
+	}
+	return golottie.NewSpriteSheetSink(create, name, spriteOpts), nil
+}
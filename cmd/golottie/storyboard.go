@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/icyrogue/golottie"
+)
+
+// runStoryboard renders sampled frames into a labeled grid and writes
+// it as PNG or PDF depending on the output extension.
+//
+// Usage: golottie storyboard [-n 12 | --markers] -i animation.json -o storyboard.png
+func runStoryboard(args []string) {
+	var (
+		input, output string
+		animationID   string
+		themeID       string
+		frames        int
+		markers       bool
+	)
+	opts := golottie.DefaultStoryboardOptions
+	flagSet := flag.NewFlagSet("storyboard", flag.ExitOnError)
+	flagSet.StringVar(&input, "input", "", "input file name")
+	flagSet.StringVar(&input, "i", "", "")
+	flagSet.StringVar(&output, "output", "", "output PNG or PDF file name")
+	flagSet.StringVar(&output, "o", "", "")
+	flagSet.StringVar(&animationID, "animation", "", "id of the dotLottie animation to render")
+	flagSet.StringVar(&themeID, "theme", "", "id of the dotLottie theme to apply")
+	flagSet.IntVar(&frames, "frames", 12, "number of evenly spaced frames")
+	flagSet.IntVar(&frames, "n", 12, "")
+	flagSet.BoolVar(&markers, "markers", false, "sample frames at the animation markers")
+	flagSet.IntVar(&opts.Columns, "columns", 0, "grid columns (default: square grid)")
+	flagSet.IntVar(&opts.CellWidth, "cell-width", opts.CellWidth, "width frames are scaled to")
+	flagSet.Usage = usage(flagSet, "golottie storyboard")
+	//nolint:errcheck // flag set exits on error
+	flagSet.Parse(args)
+	if input == "" {
+		input = flagSet.Arg(0)
+	}
+	if input == "" || output == "" {
+		log.Fatal("--output or --input is not provided, try --help")
+	}
+
+	data, err := readInput(input, animationID, themeID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	animation := golottie.NewAnimation(data)
+	sample := golottie.SampleFrames(animation.GetFramesTotal(), frames)
+	if markers {
+		if sample, opts.Labels, err = golottie.MarkerFrames(data); err != nil {
+			log.Fatal(err)
+		}
+		if len(sample) == 0 {
+			log.Fatal("animation has no markers")
+		}
+	}
+	rendered, err := renderFrames(animation, sample)
+	if err != nil {
+		log.Fatal(err)
+	}
+	img, err := golottie.NewStoryboard(animation.Metadata(), rendered, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(output), ".pdf") {
+		err = golottie.WriteImagePDF(f, img)
+	} else {
+		err = png.Encode(f, img)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// renderFrames launches the browser and renders the provided frames.
+func renderFrames(animation *golottie.AnimationData, frames []int) ([]golottie.Frame, error) {
+	ctxParent, cancelParent := context.WithTimeout(context.Background(), defTimeout*time.Second)
+	defer cancelParent()
+	ctx, cancel := golottie.NewContext(ctxParent)
+	defer cancel()
+	animation, err := animation.WithDefaultTemplate()
+	if err != nil {
+		return nil, err
+	}
+	defer animation.Close()
+	renderer := golottie.New(ctx)
+	if err = renderer.SetAnimation(animation); err != nil {
+		return nil, err
+	}
+	return renderer.RenderFrames(frames)
+}
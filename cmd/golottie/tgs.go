@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/charmbracelet/log"
+	"github.com/icyrogue/golottie"
+)
+
+// runTGS checks Telegram sticker constraints and optionally exports the
+// animation as a sticker. Exits with a non-zero code on violations.
+//
+// Usage: golottie tgs [--json] [--force] [-o sticker.tgs] animation.json
+func runTGS(args []string) {
+	var (
+		input, output string
+		asJSON, force bool
+	)
+	flagSet := flag.NewFlagSet("tgs", flag.ExitOnError)
+	flagSet.StringVar(&input, "input", "", "input file name")
+	flagSet.StringVar(&input, "i", "", "")
+	flagSet.StringVar(&output, "output", "", "sticker file name to export the animation to")
+	flagSet.StringVar(&output, "o", "", "")
+	flagSet.BoolVar(&asJSON, "json", false, "print report as JSON")
+	flagSet.BoolVar(&force, "force", false, "export the sticker even if it violates the constraints")
+	flagSet.Usage = usage(flagSet, "golottie tgs")
+	//nolint:errcheck // flag set exits on error
+	flagSet.Parse(args)
+	if input == "" {
+		input = flagSet.Arg(0)
+	}
+	if input == "" {
+		log.Fatal("--input is not provided, try --help")
+	}
+
+	data, err := readInput(input, "", "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	sticker, err := golottie.EncodeTGS(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	report, err := golottie.CheckTGS(sticker)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	failed := report.Max() >= golottie.SeverityError
+	if output != "" && (!failed || force) {
+		if err = os.WriteFile(output, sticker, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
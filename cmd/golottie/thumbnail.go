@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/icyrogue/golottie"
+)
+
+// runThumbnail renders a representative frame and writes it at every
+// requested size.
+//
+// Usage: golottie thumbnail [--frame n | --marker name | --strategy coverage] [--sizes 1200,600] -i animation.json -o poster.png
+func runThumbnail(args []string) {
+	var (
+		input, output string
+		animationID   string
+		themeID       string
+		marker        string
+		strategy      string
+		sizes         string
+	)
+	opts := golottie.DefaultPosterOptions
+	flagSet := flag.NewFlagSet("thumbnail", flag.ExitOnError)
+	flagSet.StringVar(&input, "input", "", "input file name")
+	flagSet.StringVar(&input, "i", "", "")
+	flagSet.StringVar(&output, "output", "", "output PNG or JPEG file name, sizes are added before the extension when several are requested")
+	flagSet.StringVar(&output, "o", "", "")
+	flagSet.StringVar(&animationID, "animation", "", "id of the dotLottie animation to render")
+	flagSet.StringVar(&themeID, "theme", "", "id of the dotLottie theme to apply")
+	flagSet.IntVar(&opts.Frame, "frame", -1, "frame to use as the poster")
+	flagSet.StringVar(&marker, "marker", "", "name of the marker to use as the poster")
+	flagSet.StringVar(&strategy, "strategy", "coverage", "how to pick the frame if neither --frame nor --marker is provided: coverage or average")
+	flagSet.IntVar(&opts.Samples, "samples", opts.Samples, "number of frames compared by the strategy")
+	flagSet.StringVar(&sizes, "sizes", "", "comma separated output widths (default: animation width)")
+	flagSet.Usage = usage(flagSet, "golottie thumbnail")
+	//nolint:errcheck // flag set exits on error
+	flagSet.Parse(args)
+	if input == "" {
+		input = flagSet.Arg(0)
+	}
+	if input == "" || output == "" {
+		log.Fatal("--output or --input is not provided, try --help")
+	}
+	widths := []int{0}
+	if sizes != "" {
+		widths = widths[:0]
+		for _, s := range strings.Split(sizes, ",") {
+			w, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || w <= 0 {
+				log.Fatal("invalid size", "size", s)
+			}
+			widths = append(widths, w)
+		}
+	}
+
+	data, err := readInput(input, animationID, themeID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch {
+	case marker != "":
+		opts.Strategy = golottie.PosterFrame
+		if opts.Frame, err = golottie.MarkerFrame(data, marker); err != nil {
+			log.Fatal(err)
+		}
+	case opts.Frame >= 0:
+		opts.Strategy = golottie.PosterFrame
+	case strategy == "coverage":
+		opts.Strategy = golottie.PosterCoverage
+	case strategy == "average":
+		opts.Strategy = golottie.PosterAverage
+	default:
+		log.Fatal("unknown strategy, expected coverage or average", "strategy", strategy)
+	}
+
+	frame, err := renderPoster(golottie.NewAnimation(data), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	thumbs, err := golottie.Thumbnails(frame, widths)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, thumb := range thumbs {
+		name := output
+		if len(thumbs) > 1 {
+			ext := filepath.Ext(output)
+			name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(output, ext), thumb.Rect.Dx(), ext)
+		}
+		if err = writeImage(name, thumb); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("frame %d written to %s\n", frame.Num, name)
+	}
+}
+
+// renderPoster launches the browser and renders the poster frame.
+func renderPoster(animation *golottie.AnimationData, opts golottie.PosterOptions) (golottie.Frame, error) {
+	ctxParent, cancelParent := context.WithTimeout(context.Background(), defTimeout*time.Second)
+	defer cancelParent()
+	ctx, cancel := golottie.NewContext(ctxParent)
+	defer cancel()
+	animation, err := animation.WithDefaultTemplate()
+	if err != nil {
+		return golottie.Frame{}, err
+	}
+	defer animation.Close()
+	renderer := golottie.New(ctx)
+	if err = renderer.SetAnimation(animation); err != nil {
+		return golottie.Frame{}, err
+	}
+	return renderer.Poster(opts)
+}
+
+// writeImage writes JPEG over white for .jpg and .jpeg names, PNG otherwise.
+func writeImage(name string, img image.Image) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg":
+		flat := image.NewRGBA(img.Bounds())
+		draw.Draw(flat, flat.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Rect, img, img.Bounds().Min, draw.Over)
+		err = jpeg.Encode(f, flat, &jpeg.Options{Quality: 90})
+	default:
+		err = png.Encode(f, img)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/icyrogue/golottie"
+)
+
+// runValidate prints the validation report and exits with a non-zero
+// code if the report contains issues of --fail-on severity or higher.
+//
+// Usage: golottie validate [--json] [--players svg,ios] [--fail-on error] animation.json
+func runValidate(args []string) {
+	var (
+		input   string
+		asJSON  bool
+		players string
+		failOn  string
+	)
+	flagSet := flag.NewFlagSet("validate", flag.ExitOnError)
+	flagSet.StringVar(&input, "input", "", "input file name")
+	flagSet.StringVar(&input, "i", "", "")
+	flagSet.BoolVar(&asJSON, "json", false, "print report as JSON")
+	flagSet.StringVar(&players, "players", strings.Join(golottie.Players, ","), "comma separated players to check compatibility with")
+	flagSet.StringVar(&failOn, "fail-on", golottie.SeverityError.String(), "minimal severity to exit with non-zero code (info, warning, error)")
+	flagSet.Usage = usage(flagSet, "golottie validate")
+	//nolint:errcheck // flag set exits on error
+	flagSet.Parse(args)
+	if input == "" {
+		input = flagSet.Arg(0)
+	}
+	if input == "" {
+		log.Fatal("--input is not provided, try --help")
+	}
+	threshold, err := golottie.ParseSeverity(failOn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data, err := readInput(input, "", "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	report, err := golottie.NewAnimation(data).Validate()
+	if err != nil {
+		log.Fatal(err)
+	}
+	report = report.Filter(strings.Split(players, ",")...)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if report.Max() >= threshold {
+		os.Exit(1)
+	}
+}
@@ -5,10 +5,28 @@ import (
 	"errors"
 )
 
+const (
+	// Version is the golottie version recorded in render manifests.
+	Version = "0.1.0"
+	// LottieWebVersion is the version of lottie-web in the default template.
+	LottieWebVersion = "5.10.0"
+)
+
 var (
-	EOF                 = errors.New("EOF")
-	ErrNilAnimationData = errors.New("animation data is nil")
-	ErrNilTemplate      = errors.New("custom template is nil")
+	EOF                  = errors.New("EOF")
+	ErrNilAnimationData  = errors.New("animation data is nil")
+	ErrNilTemplate       = errors.New("custom template is nil")
+	ErrPrecompNotFound   = errors.New("precomp asset not found")
+	ErrFrameOutOfRange   = errors.New("frame is out of animation range")
+	ErrNoManifest        = errors.New("manifest.json not found")
+	ErrAnimationNotFound = errors.New("animation not found")
+	ErrThemeNotFound     = errors.New("theme not found")
+	ErrNoFrames          = errors.New("no frames were written")
+	ErrSizeBudget        = errors.New("output exceeds size budget")
+	ErrInvalidWebP       = errors.New("invalid webp")
+	ErrMarkerNotFound    = errors.New("marker not found")
+	ErrInvalidFont       = errors.New("invalid font")
+	ErrUnknownSink       = errors.New("unknown sink")
 )
 
 // Context interface is a custom context which implements context.Context
@@ -27,3 +45,24 @@ type Animation interface {
 	// GetFramesTotal returns number of frames to be rendered.
 	GetFramesTotal() int
 }
+
+// Sizer interface is an optional interface implemented by animations
+// which know their own dimensions. Renderer uses it to set the viewport size.
+type Sizer interface {
+	GetWidth() int
+	GetHeight() int
+}
+
+// Describer interface is an optional interface implemented by animations
+// which know their metadata. Renderer passes it to sinks in [Renderer.RenderAll].
+type Describer interface {
+	Metadata() Metadata
+}
+
+// Sink receives rendered frames. Frames are written between Open and
+// Close, sinks which need all frames write their output on Close.
+type Sink interface {
+	Open(meta Metadata) error
+	WriteFrame(f Frame) error
+	Close() error
+}
@@ -0,0 +1,355 @@
+package golottie
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// dotLottie directories, the first one is used by dotLottie 1.0
+// and the second one by dotLottie 2.0.
+var (
+	dotLottieAnimationDirs = []string{"animations/", "a/"}
+	dotLottieImageDirs     = []string{"images/", "i/"}
+	dotLottieThemeDirs     = []string{"themes/", "t/"}
+)
+
+// DotLottie is a [dotLottie] container holding one or more animations
+// with their images and themes.
+//
+// [dotLottie]: https://dotlottie.io/spec/
+type DotLottie struct {
+	Manifest DotLottieManifest
+	files    map[string][]byte
+}
+
+// DotLottieManifest is the dotLottie manifest.json.
+type DotLottieManifest struct {
+	Version           string               `json:"version,omitempty"`
+	Generator         string               `json:"generator,omitempty"`
+	Author            string               `json:"author,omitempty"`
+	ActiveAnimationID string               `json:"activeAnimationId,omitempty"`
+	Animations        []DotLottieAnimation `json:"animations"`
+	Themes            []DotLottieTheme     `json:"themes,omitempty"`
+}
+
+// DotLottieAnimation is an animation entry of the dotLottie manifest.
+type DotLottieAnimation struct {
+	ID           string `json:"id"`
+	InitialTheme string `json:"initialTheme,omitempty"`
+}
+
+// DotLottieTheme is a theme entry of the dotLottie manifest.
+type DotLottieTheme struct {
+	ID         string   `json:"id"`
+	Animations []string `json:"animations,omitempty"`
+}
+
+// IsDotLottie reports whether data looks like a dotLottie (zip) container.
+func IsDotLottie(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+// ReadDotLottie reads a dotLottie container.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("animation.lottie")
+//	container, err := golottie.ReadDotLottie(data)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	a, err := container.Animation("", "")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	animation, err := golottie.NewAnimation(a).WithDefaultTemplate()
+func ReadDotLottie(data []byte) (*DotLottie, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading dotLottie: %w", err)
+	}
+	d := &DotLottie{files: make(map[string][]byte)}
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error reading dotLottie: %w", err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading dotLottie: %w", err)
+		}
+		d.files[strings.TrimPrefix(f.Name, "/")] = b
+	}
+	manifest, ok := d.files["manifest.json"]
+	if !ok {
+		return nil, fmt.Errorf("error reading dotLottie: %w", ErrNoManifest)
+	}
+	if err = json.Unmarshal(manifest, &d.Manifest); err != nil {
+		return nil, fmt.Errorf("error reading dotLottie manifest: %w", err)
+	}
+	return d, nil
+}
+
+// Animation returns Lottie JSON of the animation with the provided id,
+// or the active animation if id is empty. Bundled images are embedded into
+// the animation as data URIs and the theme with the provided id, or the
+// animation initial theme if themeID is empty, is applied to animation slots.
+func (d *DotLottie) Animation(id, themeID string) ([]byte, error) {
+	entry, err := d.animation(id)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := d.lookup(dotLottieAnimationDirs, entry.ID+".json")
+	if !ok {
+		return nil, fmt.Errorf("error reading dotLottie animation %q: %w", entry.ID, ErrAnimationNotFound)
+	}
+	var animation map[string]interface{}
+	if err = json.Unmarshal(data, &animation); err != nil {
+		return nil, fmt.Errorf("error reading dotLottie animation %q: %w", entry.ID, err)
+	}
+	d.embedImages(animation)
+	if themeID == "" {
+		themeID = entry.InitialTheme
+	}
+	if themeID != "" {
+		if err = d.applyTheme(animation, entry.ID, themeID); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(animation)
+}
+
+func (d *DotLottie) animation(id string) (DotLottieAnimation, error) {
+	if id == "" {
+		id = d.Manifest.ActiveAnimationID
+	}
+	for _, a := range d.Manifest.Animations {
+		if a.ID == id || id == "" {
+			return a, nil
+		}
+	}
+	return DotLottieAnimation{}, fmt.Errorf("error reading dotLottie animation %q: %w", id, ErrAnimationNotFound)
+}
+
+func (d *DotLottie) lookup(dirs []string, name string) ([]byte, bool) {
+	for _, dir := range dirs {
+		if data, ok := d.files[dir+name]; ok {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// embedImages replaces bundled image paths with data URIs.
+func (d *DotLottie) embedImages(animation map[string]interface{}) {
+	for _, a := range asSlice(animation["assets"]) {
+		asset, ok := a.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		p, _ := asset["p"].(string)
+		if _, isPrecomp := asset["layers"]; isPrecomp || p == "" || strings.HasPrefix(p, "data:") {
+			continue
+		}
+		// images are looked up by their path first, so images with the
+		// same name in different directories aren't mixed up
+		u, _ := asset["u"].(string)
+		data, ok := d.files[strings.TrimPrefix(path.Clean("/"+u+p), "/")]
+		if !ok {
+			if data, ok = d.lookup(dotLottieImageDirs, path.Base(p)); !ok {
+				continue
+			}
+		}
+		asset["p"] = dataURI(p, data)
+		asset["u"] = ""
+		asset["e"] = 1
+	}
+}
+
+// dotLottieThemeRule is a dotLottie 2.0 theme rule overriding a slot value.
+type dotLottieThemeRule struct {
+	ID         string          `json:"id"`
+	Value      json.RawMessage `json:"value"`
+	Keyframes  json.RawMessage `json:"keyframes"`
+	Animations []string        `json:"animations"`
+}
+
+// appliesTo tells whether the rule applies to the animation, rules
+// without animations apply to every animation.
+func (r dotLottieThemeRule) appliesTo(animationID string) bool {
+	if len(r.Animations) == 0 {
+		return true
+	}
+	for _, id := range r.Animations {
+		if id == animationID {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTheme overrides the animation slots with the theme rules, rules
+// scoped to other animations are skipped.
+func (d *DotLottie) applyTheme(animation map[string]interface{}, animationID, themeID string) error {
+	data, ok := d.lookup(dotLottieThemeDirs, themeID+".json")
+	if !ok {
+		return fmt.Errorf("error applying dotLottie theme %q: %w", themeID, ErrThemeNotFound)
+	}
+	var theme struct {
+		Rules []dotLottieThemeRule `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return fmt.Errorf("error applying dotLottie theme %q: %w", themeID, err)
+	}
+	slots, ok := animation["slots"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for _, rule := range theme.Rules {
+		slot, ok := slots[rule.ID].(map[string]interface{})
+		if !ok || !rule.appliesTo(animationID) {
+			continue
+		}
+		var prop map[string]interface{}
+		switch {
+		case len(rule.Keyframes) > 0:
+			var keyframes []interface{}
+			if err := json.Unmarshal(rule.Keyframes, &keyframes); err != nil {
+				return fmt.Errorf("error applying dotLottie theme rule %q: %w", rule.ID, err)
+			}
+			prop = map[string]interface{}{"a": 1, "k": keyframes}
+		case len(rule.Value) > 0:
+			var value interface{}
+			if err := json.Unmarshal(rule.Value, &value); err != nil {
+				return fmt.Errorf("error applying dotLottie theme rule %q: %w", rule.ID, err)
+			}
+			prop = map[string]interface{}{"a": 0, "k": value}
+		default:
+			continue
+		}
+		slot["p"] = prop
+	}
+	return nil
+}
+
+// WriteDotLottie writes a dotLottie container with a single animation.
+// Images referenced by the animation are read from assets and bundled,
+// JSON themes found in the "themes" directory of assets are bundled as well.
+// Assets can be nil if the animation doesn't reference any files.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("animation.json")
+//	f, _ := os.Create("animation.lottie")
+//	defer f.Close()
+//	err := golottie.WriteDotLottie(f, "animation", data, os.DirFS("."))
+func WriteDotLottie(w io.Writer, id string, animation []byte, assets fs.FS) error {
+	var root map[string]interface{}
+	if err := json.Unmarshal(animation, &root); err != nil {
+		return fmt.Errorf("error writing dotLottie: %w", err)
+	}
+	zw := zip.NewWriter(w)
+	write := func(name string, data []byte) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+
+	// entries maps image paths to their entry names, names are made
+	// unique since images are stored in a single directory
+	entries := make(map[string]string)
+	taken := make(map[string]bool)
+	for _, a := range asSlice(root["assets"]) {
+		asset, ok := a.(map[string]interface{})
+		if !ok || assets == nil {
+			continue
+		}
+		p, _ := asset["p"].(string)
+		u, _ := asset["u"].(string)
+		if _, isPrecomp := asset["layers"]; isPrecomp || p == "" || strings.HasPrefix(p, "data:") {
+			continue
+		}
+		src := path.Clean(strings.TrimPrefix(u+p, "/"))
+		name, ok := entries[src]
+		if !ok {
+			data, err := fs.ReadFile(assets, src)
+			if err != nil {
+				return fmt.Errorf("error writing dotLottie image %q: %w", u+p, err)
+			}
+			name = path.Base(p)
+			ext := path.Ext(name)
+			for i := 2; taken[name]; i++ {
+				name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path.Base(p), ext), i, ext)
+			}
+			if err = write(dotLottieImageDirs[0]+name, data); err != nil {
+				return err
+			}
+			entries[src], taken[name] = name, true
+		}
+		asset["u"] = "/" + dotLottieImageDirs[0]
+		asset["p"] = name
+		asset["e"] = 0
+	}
+
+	manifest := DotLottieManifest{
+		Version:           "1",
+		Generator:         "golottie",
+		ActiveAnimationID: id,
+		Animations:        []DotLottieAnimation{{ID: id}},
+	}
+	if assets != nil {
+		themes, _ := fs.Glob(assets, "themes/*.json")
+		for _, theme := range themes {
+			data, err := fs.ReadFile(assets, theme)
+			if err != nil {
+				return err
+			}
+			themeID := strings.TrimSuffix(path.Base(theme), ".json")
+			if err = write(dotLottieThemeDirs[0]+themeID+".json", data); err != nil {
+				return err
+			}
+			manifest.Themes = append(manifest.Themes, DotLottieTheme{ID: themeID, Animations: []string{id}})
+		}
+	}
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+	if err = write(dotLottieAnimationDirs[0]+id+".json", data); err != nil {
+		return err
+	}
+	data, err = json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err = write("manifest.json", data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// dataURI returns base64 data URI of the file with the provided name.
+func dataURI(name string, data []byte) string {
+	mimeType := mime.TypeByExtension(path.Ext(name))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,93 @@
+package golottie
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmood/gson"
+)
+
+var slotAnimData = []byte(`{"v":"5.12.0","fr":30,"ip":0,"op":30,"w":64,"h":64,
+"slots":{"fill":{"p":{"a":0,"k":[1,0,0,1]}}},
+"assets":[{"id":"img","w":1,"h":1,"u":"images/","p":"dot.png","e":0}],
+"layers":[{"ind":1,"ty":2,"refId":"img","ip":0,"op":30,"st":0,"ks":{}}]}`)
+
+func Test_DotLottie(t *testing.T) {
+	assets := fstest.MapFS{
+		"images/dot.png":    {Data: []byte("\x89PNG\r\n\x1a\n")},
+		"themes/dark.json":  {Data: []byte(`{"rules":[{"id":"fill","type":"Color","value":[0,0,0,1]},{"id":"missing","value":1}]}`)},
+		"themes/other.json": {Data: []byte(`{"rules":[{"id":"fill","type":"Color","value":[0,0,1,1],"animations":["other"]}]}`)},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, WriteDotLottie(&buf, "slots", slotAnimData, assets))
+	assert.True(t, IsDotLottie(buf.Bytes()))
+	assert.False(t, IsDotLottie(slotAnimData))
+
+	d, err := ReadDotLottie(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, "slots", d.Manifest.ActiveAnimationID)
+	assert.Len(t, d.Manifest.Themes, 2)
+
+	t.Run("Default_animation", func(t *testing.T) {
+		data, err := d.Animation("", "")
+		assert.NoError(t, err)
+		animation := gson.New(data)
+		assert.True(t, strings.HasPrefix(animation.Get("assets.0.p").Str(), "data:image/png;base64,"))
+		assert.Equal(t, 1, animation.Get("assets.0.e").Int())
+		assert.Equal(t, 1.0, animation.Get("slots.fill.p.k.0").Num())
+		assert.Equal(t, 30, NewAnimation(data).GetFramesTotal())
+	})
+	t.Run("Theme", func(t *testing.T) {
+		data, err := d.Animation("slots", "dark")
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, gson.New(data).Get("slots.fill.p.k.0").Num())
+		assert.False(t, gson.New(data).Has("slots.missing"))
+	})
+	t.Run("Theme_other_animation", func(t *testing.T) {
+		data, err := d.Animation("slots", "other")
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, gson.New(data).Get("slots.fill.p.k.0").Num())
+	})
+	t.Run("Missing", func(t *testing.T) {
+		_, err := d.Animation("(°ロ°)", "")
+		assert.ErrorIs(t, err, ErrAnimationNotFound)
+		_, err = d.Animation("", "light")
+		assert.ErrorIs(t, err, ErrThemeNotFound)
+	})
+	t.Run("Same_image_names", func(t *testing.T) {
+		animation := []byte(`{"v":"5.12.0","fr":30,"ip":0,"op":30,"w":64,"h":64,
+"assets":[{"id":"a","u":"a/","p":"img.png"},{"id":"b","u":"b/","p":"img.png"},{"id":"c","u":"a/","p":"img.png"}],
+"layers":[]}`)
+		var buf bytes.Buffer
+		assert.NoError(t, WriteDotLottie(&buf, "same", animation, fstest.MapFS{
+			"a/img.png": {Data: []byte("a")},
+			"b/img.png": {Data: []byte("b")},
+		}))
+		d, err := ReadDotLottie(buf.Bytes())
+		assert.NoError(t, err)
+		data, err := d.Animation("", "")
+		assert.NoError(t, err)
+		assets := gson.New(data).Get("assets")
+		assert.Equal(t, dataURI("img.png", []byte("a")), assets.Get("0.p").Str())
+		assert.Equal(t, dataURI("img.png", []byte("b")), assets.Get("1.p").Str())
+		assert.Equal(t, assets.Get("0.p").Str(), assets.Get("2.p").Str())
+	})
+	t.Run("Missing_image", func(t *testing.T) {
+		assert.Error(t, WriteDotLottie(&bytes.Buffer{}, "slots", slotAnimData, fstest.MapFS{}))
+	})
+	t.Run("No_manifest", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		_, err := zw.Create("animations/a.json")
+		assert.NoError(t, err)
+		assert.NoError(t, zw.Close())
+		_, err = ReadDotLottie(buf.Bytes())
+		assert.ErrorIs(t, err, ErrNoManifest)
+		_, err = ReadDotLottie(animData)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,357 @@
+package golottie
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// sfnt table tags used by the subsetter.
+const (
+	sfntTrueType   = 0x00010000
+	sfntTrueTypeID = "true"
+	sfntOpenType   = "OTTO"
+)
+
+// composite glyph component flags.
+const (
+	glyfArgsAreWords   = 0x0001
+	glyfHaveScale      = 0x0008
+	glyfMoreComponents = 0x0020
+	glyfHaveXYScale    = 0x0040
+	glyfHave2x2        = 0x0080
+)
+
+// fontMIMEType returns the MIME type of font data by its signature.
+func fontMIMEType(data []byte) string {
+	if len(data) < 4 {
+		return "application/octet-stream"
+	}
+	switch string(data[:4]) {
+	case "wOFF":
+		return "font/woff"
+	case "wOF2":
+		return "font/woff2"
+	case sfntOpenType:
+		return "font/otf"
+	}
+	return "font/ttf"
+}
+
+// sfntTable is a table of an sfnt font.
+type sfntTable struct {
+	tag  string
+	data []byte
+}
+
+// subsetFont strips outlines of glyphs not used by text from TrueType
+// font. Glyph ids are retained, so the other tables stay valid and
+// the font is only rebuilt with smaller glyf and loca tables. Fonts
+// without TrueType outlines are returned as is.
+func subsetFont(data []byte, text string) ([]byte, error) {
+	if len(data) < 12 {
+		return nil, ErrInvalidFont
+	}
+	if version := binary.BigEndian.Uint32(data); version != sfntTrueType && string(data[:4]) != sfntTrueTypeID {
+		return data, nil
+	}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		return nil, ErrInvalidFont
+	}
+	tables := make([]sfntTable, 0, numTables)
+	byTag := make(map[string][]byte, numTables)
+	for i := 0; i < numTables; i++ {
+		rec := data[12+16*i:]
+		tag := string(rec[:4])
+		offset, length := int(binary.BigEndian.Uint32(rec[8:])), int(binary.BigEndian.Uint32(rec[12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, fmt.Errorf("error reading %q table: %w", tag, ErrInvalidFont)
+		}
+		tables = append(tables, sfntTable{tag: tag, data: data[offset : offset+length]})
+		byTag[tag] = data[offset : offset+length]
+	}
+	head, maxp, loca, glyf, cmap := byTag["head"], byTag["maxp"], byTag["loca"], byTag["glyf"], byTag["cmap"]
+	if glyf == nil || loca == nil {
+		return data, nil
+	}
+	if len(head) < 54 || len(maxp) < 6 || cmap == nil {
+		return nil, ErrInvalidFont
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	offsets, err := readLoca(loca, numGlyphs, binary.BigEndian.Uint16(head[50:]) == 1)
+	if err != nil {
+		return nil, err
+	}
+	lookup, err := readCmap(cmap)
+	if err != nil {
+		return nil, err
+	}
+
+	// .notdef is always kept, components of composite glyphs are added
+	// as they are discovered
+	keep := map[int]bool{0: true}
+	queue := []int{0}
+	for _, r := range text {
+		if gid := lookup(r); gid > 0 && gid < numGlyphs && !keep[gid] {
+			keep[gid] = true
+			queue = append(queue, gid)
+		}
+	}
+	for len(queue) > 0 {
+		gid := queue[0]
+		queue = queue[1:]
+		if offsets[gid] > offsets[gid+1] || offsets[gid+1] > len(glyf) {
+			return nil, fmt.Errorf("error reading glyph %d: %w", gid, ErrInvalidFont)
+		}
+		for _, c := range glyphComponents(glyf[offsets[gid]:offsets[gid+1]]) {
+			if c < numGlyphs && !keep[c] {
+				keep[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	newGlyf := make([]byte, 0, len(glyf))
+	newLoca := make([]byte, 0, 4*(numGlyphs+1))
+	for gid := 0; gid < numGlyphs; gid++ {
+		newLoca = binary.BigEndian.AppendUint32(newLoca, uint32(len(newGlyf)))
+		if !keep[gid] {
+			continue
+		}
+		newGlyf = append(newGlyf, glyf[offsets[gid]:offsets[gid+1]]...)
+		for len(newGlyf)%4 != 0 {
+			newGlyf = append(newGlyf, 0)
+		}
+	}
+	newLoca = binary.BigEndian.AppendUint32(newLoca, uint32(len(newGlyf)))
+	newHead := append([]byte(nil), head...)
+	// long loca offsets, checksum adjustment is recomputed below
+	binary.BigEndian.PutUint16(newHead[50:], 1)
+	binary.BigEndian.PutUint32(newHead[8:], 0)
+
+	subset := tables[:0]
+	for _, t := range tables {
+		switch t.tag {
+		case "glyf":
+			t.data = newGlyf
+		case "loca":
+			t.data = newLoca
+		case "head":
+			t.data = newHead
+		case "DSIG":
+			// the signature is invalid once the font is modified
+			continue
+		}
+		subset = append(subset, t)
+	}
+	return writeSfnt(subset), nil
+}
+
+// readLoca returns numGlyphs+1 glyph offsets into the glyf table.
+func readLoca(loca []byte, numGlyphs int, long bool) ([]int, error) {
+	size := 2
+	if long {
+		size = 4
+	}
+	if len(loca) < size*(numGlyphs+1) {
+		return nil, fmt.Errorf("error reading loca table: %w", ErrInvalidFont)
+	}
+	offsets := make([]int, numGlyphs+1)
+	for i := range offsets {
+		if long {
+			offsets[i] = int(binary.BigEndian.Uint32(loca[4*i:]))
+		} else {
+			offsets[i] = 2 * int(binary.BigEndian.Uint16(loca[2*i:]))
+		}
+	}
+	return offsets, nil
+}
+
+// glyphComponents returns glyph ids referenced by composite glyph.
+func glyphComponents(glyph []byte) []int {
+	if len(glyph) < 10 || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+		return nil
+	}
+	var components []int
+	for p := 10; p+4 <= len(glyph); {
+		flags := binary.BigEndian.Uint16(glyph[p:])
+		components = append(components, int(binary.BigEndian.Uint16(glyph[p+2:])))
+		p += 4
+		if flags&glyfArgsAreWords != 0 {
+			p += 4
+		} else {
+			p += 2
+		}
+		switch {
+		case flags&glyfHaveScale != 0:
+			p += 2
+		case flags&glyfHaveXYScale != 0:
+			p += 4
+		case flags&glyfHave2x2 != 0:
+			p += 8
+		}
+		if flags&glyfMoreComponents == 0 {
+			break
+		}
+	}
+	return components
+}
+
+// readCmap returns rune to glyph id lookup using the best Unicode
+// subtable of format 4 or 12.
+func readCmap(cmap []byte) (func(rune) int, error) {
+	if len(cmap) < 4 {
+		return nil, fmt.Errorf("error reading cmap table: %w", ErrInvalidFont)
+	}
+	best, bestScore := -1, 0
+	for i := 0; i < int(binary.BigEndian.Uint16(cmap[2:])); i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(cmap) {
+			break
+		}
+		platform, encoding := binary.BigEndian.Uint16(cmap[rec:]), binary.BigEndian.Uint16(cmap[rec+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[rec+4:]))
+		if offset+2 > len(cmap) {
+			continue
+		}
+		format := binary.BigEndian.Uint16(cmap[offset:])
+		score := 0
+		switch {
+		case format == 12 && (platform == 0 || platform == 3 && encoding == 10):
+			score = 2
+		case format == 4 && (platform == 0 || platform == 3 && encoding == 1):
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = offset, score
+		}
+	}
+	if best < 0 {
+		return nil, fmt.Errorf("error reading cmap table: no unicode subtable: %w", ErrInvalidFont)
+	}
+	sub := cmap[best:]
+	if bestScore == 2 {
+		return readCmap12(sub)
+	}
+	return readCmap4(sub)
+}
+
+func readCmap4(sub []byte) (func(rune) int, error) {
+	if len(sub) < 14 {
+		return nil, ErrInvalidFont
+	}
+	segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	ends, starts, deltas, ranges := 14, 16+2*segCount, 16+4*segCount, 16+6*segCount
+	if len(sub) < ranges+2*segCount {
+		return nil, ErrInvalidFont
+	}
+	u16 := func(p int) int {
+		if p+2 > len(sub) {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(sub[p:]))
+	}
+	return func(r rune) int {
+		c := int(r)
+		if c > 0xffff {
+			return 0
+		}
+		for i := 0; i < segCount; i++ {
+			if c > u16(ends+2*i) {
+				continue
+			}
+			start := u16(starts + 2*i)
+			if c < start {
+				return 0
+			}
+			delta, rangeOffset := u16(deltas+2*i), u16(ranges+2*i)
+			if rangeOffset == 0 {
+				return (c + delta) & 0xffff
+			}
+			gid := u16(ranges + 2*i + rangeOffset + 2*(c-start))
+			if gid == 0 {
+				return 0
+			}
+			return (gid + delta) & 0xffff
+		}
+		return 0
+	}, nil
+}
+
+func readCmap12(sub []byte) (func(rune) int, error) {
+	if len(sub) < 16 {
+		return nil, ErrInvalidFont
+	}
+	numGroups := int(binary.BigEndian.Uint32(sub[12:]))
+	if len(sub) < 16+12*numGroups {
+		return nil, ErrInvalidFont
+	}
+	return func(r rune) int {
+		c := uint32(r)
+		// groups are sorted by the start code
+		i := sort.Search(numGroups, func(i int) bool {
+			return binary.BigEndian.Uint32(sub[16+12*i+4:]) >= c
+		})
+		if i == numGroups {
+			return 0
+		}
+		group := sub[16+12*i:]
+		start := binary.BigEndian.Uint32(group)
+		if c < start {
+			return 0
+		}
+		return int(binary.BigEndian.Uint32(group[8:]) + c - start)
+	}, nil
+}
+
+// writeSfnt writes TrueType font with tables sorted by tag and
+// recomputes the checksums.
+func writeSfnt(tables []sfntTable) []byte {
+	sort.Slice(tables, func(i, j int) bool { return tables[i].tag < tables[j].tag })
+	n := len(tables)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= n {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 16
+
+	out := binary.BigEndian.AppendUint32(nil, sfntTrueType)
+	out = binary.BigEndian.AppendUint16(out, uint16(n))
+	out = binary.BigEndian.AppendUint16(out, uint16(searchRange))
+	out = binary.BigEndian.AppendUint16(out, uint16(entrySelector))
+	out = binary.BigEndian.AppendUint16(out, uint16(n*16-searchRange))
+	offset := 12 + 16*n
+	headOffset := -1
+	for _, t := range tables {
+		out = append(out, t.tag...)
+		out = binary.BigEndian.AppendUint32(out, sfntChecksum(t.data))
+		out = binary.BigEndian.AppendUint32(out, uint32(offset))
+		out = binary.BigEndian.AppendUint32(out, uint32(len(t.data)))
+		if t.tag == "head" {
+			headOffset = offset
+		}
+		offset += (len(t.data) + 3) &^ 3
+	}
+	for _, t := range tables {
+		out = append(out, t.data...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	if headOffset >= 0 {
+		binary.BigEndian.PutUint32(out[headOffset+8:], 0xb1b0afba-sfntChecksum(out))
+	}
+	return out
+}
+
+// sfntChecksum sums data as big endian uint32 values padded with zeros.
+func sfntChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -0,0 +1,80 @@
+package golottie
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFont builds TrueType font mapping 'A' and 'B' to glyphs 1 and 2,
+// glyph 3 is a composite of glyph 1 mapped to 'C'.
+func testFont() []byte {
+	simple := []byte{0, 1, 0, 0, 0, 0, 0, 10, 0, 10, 0, 0, 0, 0, 1, 0}
+	composite := []byte{0xff, 0xff, 0, 0, 0, 0, 0, 10, 0, 10, 0, 0, 0, 1, 0, 0}
+	glyphs := [][]byte{simple, simple, simple, composite}
+	var glyf, loca []byte
+	for _, g := range glyphs {
+		loca = binary.BigEndian.AppendUint16(loca, uint16(len(glyf)/2))
+		glyf = append(glyf, g...)
+	}
+	loca = binary.BigEndian.AppendUint16(loca, uint16(len(glyf)/2))
+
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint32(head[12:], 0x5f0f3cf5)
+	maxp := []byte{0, 0, 0x50, 0, 0, byte(len(glyphs))}
+	// format 4 subtable with 'A'-'C' segment and the final 0xffff one
+	sub := []byte{0, 4, 0, 32, 0, 0, 0, 4, 0, 4, 0, 1, 0, 0,
+		0, 'C', 0xff, 0xff, 0, 0,
+		0, 'A', 0xff, 0xff,
+		0xff, 0xc0, 0, 1,
+		0, 0, 0, 0}
+	cmap := append([]byte{0, 0, 0, 1, 0, 3, 0, 1, 0, 0, 0, 12}, sub...)
+	return writeSfnt([]sfntTable{
+		{tag: "head", data: head},
+		{tag: "maxp", data: maxp},
+		{tag: "cmap", data: cmap},
+		{tag: "loca", data: loca},
+		{tag: "glyf", data: glyf},
+		{tag: "DSIG", data: []byte{0, 0, 0, 1}},
+	})
+}
+
+func Test_subsetFont(t *testing.T) {
+	font := testFont()
+	tables := func(data []byte) map[string][]byte {
+		byTag := make(map[string][]byte)
+		for i := 0; i < int(binary.BigEndian.Uint16(data[4:])); i++ {
+			rec := data[12+16*i:]
+			offset, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+			byTag[string(rec[:4])] = data[offset : offset+length]
+		}
+		return byTag
+	}
+
+	subset, err := subsetFont(font, "C")
+	require.NoError(t, err)
+	byTag := tables(subset)
+	assert.NotContains(t, byTag, "DSIG")
+	assert.Equal(t, uint16(1), binary.BigEndian.Uint16(byTag["head"][50:]))
+	offsets, err := readLoca(byTag["loca"], 4, true)
+	require.NoError(t, err)
+	// .notdef, the composite and its component are kept
+	assert.Equal(t, []int{0, 16, 32, 32, 48}, offsets)
+	assert.Equal(t, uint32(0xb1b0afba), sfntChecksum(subset))
+
+	lookup, err := readCmap(byTag["cmap"])
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 0}, []int{lookup('A'), lookup('B'), lookup('C'), lookup('D')})
+
+	otf := []byte("OTTO\x00\x00\x00\x00\x00\x00\x00\x00")
+	subset, err = subsetFont(otf, "A")
+	require.NoError(t, err)
+	assert.Equal(t, otf, subset)
+	assert.Equal(t, "font/otf", fontMIMEType(otf))
+	assert.Equal(t, "font/ttf", fontMIMEType(font))
+
+	_, err = subsetFont(font[:20], "A")
+	assert.ErrorIs(t, err, ErrInvalidFont)
+}
@@ -0,0 +1,54 @@
+package golottie
+
+import "io"
+
+// Frame is a rendered animation frame.
+type Frame struct {
+	// Num is the zero based animation frame number.
+	Num int
+	// Data contains the encoded frame image, PNG unless stated otherwise.
+	Data []byte
+}
+
+// ImageFormat is the format frames are captured in.
+type ImageFormat string
+
+const (
+	FormatPNG  ImageFormat = "png"
+	FormatJPEG ImageFormat = "jpeg"
+	FormatWebP ImageFormat = "webp"
+	// FormatSVG is the SVG markup of the player, see [Renderer.RenderFrameSVG].
+	FormatSVG ImageFormat = "svg"
+)
+
+// FrameFormatter is implemented by sinks expecting frames
+// rendered with [Renderer.RenderFrameAs] instead of PNG.
+type FrameFormatter interface {
+	FrameFormat() (format ImageFormat, quality int)
+}
+
+// Metadata describes the animation frames are rendered from.
+type Metadata struct {
+	Name        string
+	Width       int
+	Height      int
+	FrameRate   float64
+	FramesTotal int
+}
+
+// frameRateOr30 returns the frame rate, 30 if it's unknown.
+func (m Metadata) frameRateOr30() float64 {
+	if m.FrameRate <= 0 {
+		return 30
+	}
+	return m.FrameRate
+}
+
+// frameTime returns the start time of frame i in units per second,
+// rounded so frame durations add up to the animation duration.
+func (m Metadata) frameTime(i int, units float64) int {
+	return int(float64(i)*units/m.frameRateOr30() + 0.5)
+}
+
+// CreateFunc creates named output file for sinks writing several files.
+type CreateFunc func(name string) (io.WriteCloser, error)
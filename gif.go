@@ -0,0 +1,386 @@
+package golottie
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"sort"
+)
+
+// GIFPalette selects how GIF palettes are built.
+type GIFPalette int
+
+const (
+	// GIFPaletteGlobal builds a single palette from all frames
+	// avoiding color flicker between frames.
+	GIFPaletteGlobal GIFPalette = iota
+	// GIFPalettePerFrame builds a palette for every frame, which
+	// suits animations with colors changing over time.
+	GIFPalettePerFrame
+)
+
+// GIFDither selects how colors missing from the palette are approximated.
+type GIFDither int
+
+const (
+	GIFDitherFloydSteinberg GIFDither = iota
+	GIFDitherNone
+)
+
+// gifMinDelay is the minimal frame delay in 1/100s honored by browsers,
+// shorter delays are usually played at 10.
+const gifMinDelay = 2
+
+// GIFOptions configures [GIFSink].
+type GIFOptions struct {
+	Palette GIFPalette
+	Dither  GIFDither
+	// Colors is the palette size, up to 256 including the transparent color.
+	Colors int
+	// Transparent keeps pixels with alpha below 50% transparent, other
+	// pixels are made opaque. Frames are drawn over Background otherwise.
+	Transparent bool
+	// Background is the color transparent frames are drawn over
+	// when Transparent is false, defaults to white.
+	Background color.Color
+	// LoopCount is the number of times the animation is repeated,
+	// 0 loops forever and -1 shows every frame once.
+	LoopCount int
+	// Step keeps every n-th frame.
+	Step int
+	// MaxBytes drops more frames until the GIF fits the budget, 0 disables it.
+	MaxBytes int
+}
+
+// DefaultGIFOptions loops the animation forever using global palette
+// and Floyd-Steinberg dithering.
+var DefaultGIFOptions = GIFOptions{
+	Palette:     GIFPaletteGlobal,
+	Dither:      GIFDitherFloydSteinberg,
+	Colors:      256,
+	Transparent: true,
+	Background:  color.White,
+	Step:        1,
+}
+
+// GIFSink encodes rendered frames into an animated GIF. Frames are
+// buffered and the GIF is written to w on [GIFSink.Close] since both
+// the global palette and the size budget require all frames.
+// Frame delays are derived from the animation frame rate and the frame
+// numbers, so the frame before missing ones is held. Frames shorter than
+// 2/100s are dropped as most players don't honor such delays.
+//
+// Example:
+//
+//	sink := golottie.NewGIFSink(f, golottie.DefaultGIFOptions)
+//	sink.Open(animation.Metadata())
+//	for renderer.NextFrame() {
+//		...
+//		sink.WriteFrame(golottie.Frame{Num: n, Data: buf})
+//	}
+//	err := sink.Close()
+type GIFSink struct {
+	w      io.Writer
+	opts   GIFOptions
+	meta   Metadata
+	frames []Frame
+	hist   *histogram
+}
+
+// NewGIFSink returns GIF sink writing to w.
+func NewGIFSink(w io.Writer, opts GIFOptions) *GIFSink {
+	if opts.Colors <= 0 || opts.Colors > 256 {
+		opts.Colors = 256
+	}
+	if opts.Step < 1 {
+		opts.Step = 1
+	}
+	if opts.Background == nil {
+		opts.Background = color.White
+	}
+	return &GIFSink{w: w, opts: opts}
+}
+
+// Open prepares the sink for the animation.
+func (s *GIFSink) Open(meta Metadata) error {
+	s.meta = meta
+	s.frames = s.frames[:0]
+	s.hist = newHistogram()
+	return nil
+}
+
+// WriteFrame buffers PNG encoded frame.
+func (s *GIFSink) WriteFrame(f Frame) error {
+	if s.opts.Palette == GIFPaletteGlobal {
+		img, err := s.decode(f.Data)
+		if err != nil {
+			return fmt.Errorf("error decoding frame %d: %w", f.Num, err)
+		}
+		s.hist.add(img)
+	}
+	s.frames = append(s.frames, f)
+	return nil
+}
+
+// Close quantizes the frames and writes the GIF. If the GIF doesn't fit
+// MaxBytes even with a single frame left, the smallest GIF is still
+// written and [ErrSizeBudget] is returned.
+func (s *GIFSink) Close() error {
+	if len(s.frames) == 0 {
+		return ErrNoFrames
+	}
+	sort.SliceStable(s.frames, func(i, j int) bool { return s.frames[i].Num < s.frames[j].Num })
+	var palette color.Palette
+	if s.opts.Palette == GIFPaletteGlobal {
+		palette = s.palette(s.hist)
+	}
+	images := make([]*image.Paletted, len(s.frames))
+	nums := make([]int, len(s.frames))
+	for i, f := range s.frames {
+		nums[i] = f.Num
+		img, err := s.decode(f.Data)
+		if err != nil {
+			return fmt.Errorf("error decoding frame %d: %w", f.Num, err)
+		}
+		p := palette
+		if p == nil {
+			h := newHistogram()
+			h.add(img)
+			p = s.palette(h)
+		}
+		images[i] = s.quantize(img, p)
+	}
+	s.frames = nil
+
+	var buf bytes.Buffer
+	for step := s.opts.Step; ; step++ {
+		buf.Reset()
+		kept, err := s.encode(&buf, images, nums, step)
+		if err != nil {
+			return err
+		}
+		if s.opts.MaxBytes <= 0 || buf.Len() <= s.opts.MaxBytes {
+			break
+		}
+		if kept <= 1 {
+			if _, err = buf.WriteTo(s.w); err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %d bytes, budget is %d", ErrSizeBudget, buf.Len(), s.opts.MaxBytes)
+		}
+	}
+	_, err := buf.WriteTo(s.w)
+	return err
+}
+
+// encode writes every step-th frame and returns the number of frames kept.
+// Frame i is shown from the time of frame number nums[i], so frames missing
+// in between are held.
+func (s *GIFSink) encode(w io.Writer, images []*image.Paletted, nums []int, step int) (int, error) {
+	// delays are in hundredths of a second
+	at := func(i int) int {
+		if i == len(nums) {
+			return s.meta.frameTime(nums[i-1]+1, 100)
+		}
+		return s.meta.frameTime(nums[i], 100)
+	}
+
+	var kept []int
+	for i := 0; i < len(images); i += step {
+		if len(kept) > 0 && at(i)-at(kept[len(kept)-1]) < gifMinDelay {
+			continue
+		}
+		kept = append(kept, i)
+	}
+	g := &gif.GIF{LoopCount: s.opts.LoopCount}
+	disposal := byte(gif.DisposalNone)
+	if s.opts.Transparent {
+		disposal = gif.DisposalBackground
+	}
+	for n, i := range kept {
+		end := len(images)
+		if n+1 < len(kept) {
+			end = kept[n+1]
+		}
+		delay := at(end) - at(i)
+		if delay < gifMinDelay {
+			delay = gifMinDelay
+		}
+		g.Image = append(g.Image, images[i])
+		g.Delay = append(g.Delay, delay)
+		g.Disposal = append(g.Disposal, disposal)
+	}
+	if err := gif.EncodeAll(w, g); err != nil {
+		return 0, fmt.Errorf("error encoding gif: %w", err)
+	}
+	return len(kept), nil
+}
+
+// decode decodes the frame into non-premultiplied image applying
+// transparency options.
+func (s *GIFSink) decode(data []byte) (*image.NRGBA, error) {
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	if s.meta.Width > 0 && s.meta.Height > 0 {
+		b = image.Rect(0, 0, s.meta.Width, s.meta.Height)
+	}
+	img := image.NewNRGBA(b)
+	if !s.opts.Transparent {
+		draw.Draw(img, b, image.NewUniform(s.opts.Background), image.Point{}, draw.Src)
+		draw.Draw(img, b, src, src.Bounds().Min, draw.Over)
+		return img, nil
+	}
+	draw.Draw(img, b, src, src.Bounds().Min, draw.Src)
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] < 0x80 {
+			img.Pix[i-3], img.Pix[i-2], img.Pix[i-1], img.Pix[i] = 0, 0, 0, 0
+		} else {
+			img.Pix[i] = 0xff
+		}
+	}
+	return img, nil
+}
+
+// palette builds the palette reserving the first color for transparency.
+func (s *GIFSink) palette(h *histogram) color.Palette {
+	colors := s.opts.Colors
+	var palette color.Palette
+	if s.opts.Transparent {
+		palette = append(palette, color.NRGBA{})
+		colors--
+	}
+	return append(palette, h.medianCut(colors)...)
+}
+
+func (s *GIFSink) quantize(img *image.NRGBA, palette color.Palette) *image.Paletted {
+	dst := image.NewPaletted(img.Bounds(), palette)
+	if s.opts.Dither == GIFDitherNone {
+		draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+	} else {
+		draw.FloydSteinberg.Draw(dst, dst.Bounds(), img, img.Bounds().Min)
+	}
+	return dst
+}
+
+// histogram counts opaque colors reduced to 5 bits per channel.
+type histogram struct {
+	bins map[uint16]*bin
+}
+
+type bin struct {
+	key        uint16
+	r, g, b, n int
+}
+
+func newHistogram() *histogram {
+	return &histogram{bins: make(map[uint16]*bin)}
+}
+
+func (h *histogram) add(img *image.NRGBA) {
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		if img.Pix[i+3] == 0 {
+			continue
+		}
+		r, g, b := int(img.Pix[i]), int(img.Pix[i+1]), int(img.Pix[i+2])
+		key := uint16(r>>3)<<10 | uint16(g>>3)<<5 | uint16(b>>3)
+		c, ok := h.bins[key]
+		if !ok {
+			c = &bin{key: key}
+			h.bins[key] = c
+		}
+		c.r += r
+		c.g += g
+		c.b += b
+		c.n++
+	}
+}
+
+// medianCut returns up to n colors splitting the box with the widest
+// channel range at the weighted median until n boxes are made.
+func (h *histogram) medianCut(n int) color.Palette {
+	all := make([]*bin, 0, len(h.bins))
+	for _, b := range h.bins {
+		all = append(all, b)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].key < all[j].key })
+	boxes := [][]*bin{all}
+	for len(boxes) < n {
+		widest, channel, width := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			for c := 0; c < 3; c++ {
+				if w := binRange(box, c); w > width {
+					widest, channel, width = i, c, w
+				}
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		box := boxes[widest]
+		sort.SliceStable(box, func(i, j int) bool { return binMean(box[i], channel) < binMean(box[j], channel) })
+		total := 0
+		for _, b := range box {
+			total += b.n
+		}
+		split, count := 1, box[0].n
+		for split < len(box)-1 && count*2 < total {
+			count += box[split].n
+			split++
+		}
+		boxes[widest] = box[:split]
+		boxes = append(boxes, box[split:])
+	}
+	palette := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		var r, g, b, n int
+		for _, c := range box {
+			r += c.r
+			g += c.g
+			b += c.b
+			n += c.n
+		}
+		if n == 0 {
+			continue
+		}
+		palette = append(palette, color.NRGBA{uint8(r / n), uint8(g / n), uint8(b / n), 0xff})
+	}
+	if len(palette) == 0 {
+		palette = append(palette, color.NRGBA{A: 0xff})
+	}
+	return palette
+}
+
+func binMean(b *bin, channel int) int {
+	switch channel {
+	case 0:
+		return b.r / b.n
+	case 1:
+		return b.g / b.n
+	}
+	return b.b / b.n
+}
+
+func binRange(box []*bin, channel int) int {
+	lo, hi := 255, 0
+	for _, b := range box {
+		v := binMean(b, channel)
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	return hi - lo
+}
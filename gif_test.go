@@ -0,0 +1,151 @@
+package golottie
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pngFrame encodes a 4x4 frame filled with c, top-left pixel is transparent.
+func pngFrame(t *testing.T, c color.NRGBA) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	img.SetNRGBA(0, 0, color.NRGBA{})
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func writeGIF(t *testing.T, opts GIFOptions, frameRate float64, frames int) ([]byte, error) {
+	var buf bytes.Buffer
+	sink := NewGIFSink(&buf, opts)
+	assert.NoError(t, sink.Open(Metadata{Width: 4, Height: 4, FrameRate: frameRate, FramesTotal: frames}))
+	colors := []color.NRGBA{{R: 255, A: 255}, {G: 255, A: 255}, {B: 255, A: 255}}
+	for i := frames - 1; i >= 0; i-- {
+		assert.NoError(t, sink.WriteFrame(Frame{Num: i, Data: pngFrame(t, colors[i%len(colors)])}))
+	}
+	err := sink.Close()
+	return buf.Bytes(), err
+}
+
+func Test_GIFSink(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      func(o *GIFOptions)
+		frameRate float64
+		frames    int
+		delays    []int
+		check     func(t *testing.T, g *gif.GIF)
+	}{
+		{
+			name:      "global palette",
+			frameRate: 30,
+			frames:    3,
+			delays:    []int{3, 4, 3},
+			check: func(t *testing.T, g *gif.GIF) {
+				assert.Equal(t, 0, g.LoopCount)
+				assert.Equal(t, g.Image[0].Palette, g.Image[2].Palette)
+				r, _, _, a := g.Image[0].At(1, 1).RGBA()
+				assert.Equal(t, uint32(0xffff), r)
+				assert.Equal(t, uint32(0xffff), a)
+				_, _, b, _ := g.Image[2].At(1, 1).RGBA()
+				assert.Equal(t, uint32(0xffff), b)
+				_, _, _, a = g.Image[0].At(0, 0).RGBA()
+				assert.Zero(t, a)
+				assert.Equal(t, byte(gif.DisposalBackground), g.Disposal[0])
+			},
+		},
+		{
+			name: "per frame palette over background",
+			opts: func(o *GIFOptions) {
+				o.Palette = GIFPalettePerFrame
+				o.Dither = GIFDitherNone
+				o.Transparent = false
+				o.LoopCount = -1
+			},
+			frameRate: 25,
+			frames:    2,
+			delays:    []int{4, 4},
+			check: func(t *testing.T, g *gif.GIF) {
+				assert.Equal(t, -1, g.LoopCount)
+				assert.Len(t, g.Image[1].Palette, 2)
+				r, gr, b, a := g.Image[0].At(0, 0).RGBA()
+				assert.Equal(t, []uint32{0xffff, 0xffff, 0xffff, 0xffff}, []uint32{r, gr, b, a})
+			},
+		},
+		{
+			name:      "too short frames are dropped",
+			frameRate: 100,
+			frames:    6,
+			delays:    []int{2, 2, 2},
+		},
+		{
+			name:      "step",
+			opts:      func(o *GIFOptions) { o.Step = 2 },
+			frameRate: 10,
+			frames:    5,
+			delays:    []int{20, 20, 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultGIFOptions
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			data, err := writeGIF(t, opts, tt.frameRate, tt.frames)
+			assert.NoError(t, err)
+			g, err := gif.DecodeAll(bytes.NewReader(data))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.delays, g.Delay)
+			if tt.check != nil {
+				tt.check(t, g)
+			}
+		})
+	}
+}
+
+func Test_GIFSink_gaps(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewGIFSink(&buf, DefaultGIFOptions)
+	assert.NoError(t, sink.Open(Metadata{Width: 4, Height: 4, FrameRate: 10, FramesTotal: 6}))
+	// frames 1 and 2 are missing, frame 0 is held until frame 3
+	for _, num := range []int{0, 3, 4} {
+		assert.NoError(t, sink.WriteFrame(Frame{Num: num, Data: pngFrame(t, color.NRGBA{R: 255, A: 255})}))
+	}
+	assert.NoError(t, sink.Close())
+	g, err := gif.DecodeAll(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{30, 10, 10}, g.Delay)
+}
+
+func Test_GIFSink_MaxBytes(t *testing.T) {
+	full, err := writeGIF(t, DefaultGIFOptions, 10, 6)
+	assert.NoError(t, err)
+
+	opts := DefaultGIFOptions
+	opts.MaxBytes = len(full) - 1
+	data, err := writeGIF(t, opts, 10, 6)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(data), opts.MaxBytes)
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Less(t, len(g.Image), 6)
+
+	opts.MaxBytes = 10
+	data, err = writeGIF(t, opts, 10, 6)
+	assert.ErrorIs(t, err, ErrSizeBudget)
+	assert.NotEmpty(t, data)
+
+	_, err = writeGIF(t, opts, 10, 0)
+	assert.ErrorIs(t, err, ErrNoFrames)
+}
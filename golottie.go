@@ -11,16 +11,29 @@
 package golottie
 
 import (
+	"context"
 	"fmt"
 	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
 )
 
+const (
+	defWidth  = 1920
+	defHeight = 1080
+)
+
 type Renderer struct {
 	framesDone  int
 	framesTotal int
+	width       int
+	height      int
+	meta        Metadata
 	ctx         Context
+	cache       *RenderCache
+	cacheKey    string
+	lottieWeb   string
 }
 
 // New creates a new renderer instance with parent context.
@@ -30,21 +43,38 @@ func New(ctx Context) (renderer *Renderer) {
 
 // SetAnimation sets renderer animation.
 // Renderer calls [AnimationData.GetFramesTotal] and [AnimationData.GetURL]
-// to update the animation.
+// to update the animation. If the animation implements [Sizer] its dimensions
+// are used as the viewport size, otherwise the viewport defaults to 1920x1080.
 func (r *Renderer) SetAnimation(animation Animation) error {
 	r.framesTotal = animation.GetFramesTotal()
+	r.framesDone = 0
+	r.width, r.height = defWidth, defHeight
+	if s, ok := animation.(Sizer); ok && s.GetWidth() > 0 && s.GetHeight() > 0 {
+		r.width, r.height = s.GetWidth(), s.GetHeight()
+	}
+	r.meta = Metadata{Width: r.width, Height: r.height, FramesTotal: r.framesTotal}
+	if d, ok := animation.(Describer); ok {
+		r.meta = d.Metadata()
+	}
 	if err := chromedp.Run(r.ctx,
-		//TODO: pass WxH and BG with the animation
-		emulation.SetDefaultBackgroundColorOverride().WithColor(&cdp.RGBA{0, 0, 0, 0}),
-		chromedp.EmulateViewport(1920, 1080),
+		//TODO: pass BG with the animation
+		emulation.SetDefaultBackgroundColorOverride().WithColor(&cdp.RGBA{R: 0, G: 0, B: 0, A: 0}),
+		chromedp.EmulateViewport(int64(r.width), int64(r.height)),
 		chromedp.Navigate(animation.GetURL()),
 		chromedp.WaitReady(`//*[@id="lottie"]`),
+		chromedp.Evaluate(`typeof lottie !== "undefined" && lottie.version || ""`, &r.lottieWeb),
 	); err != nil {
 		return err
 	}
 	return nil
 }
 
+// LottieWebVersion returns the version of lottie-web the page of the
+// current animation reports, empty if the template doesn't expose it.
+func (r *Renderer) LottieWebVersion() string {
+	return r.lottieWeb
+}
+
 // NextFrame advances the current animation frame by one.
 // Returns [EOF] if there aren't any frames left.
 func (r *Renderer) NextFrame() bool {
@@ -62,6 +92,22 @@ func (r *Renderer) NextFrame() bool {
 	return true
 }
 
+// GoToFrame moves the animation to the provided frame, so that
+// [Renderer.NextFrame] continues from the frame after it.
+// Returns [ErrFrameOutOfRange] if the animation doesn't have the frame.
+func (r *Renderer) GoToFrame(frame int) error {
+	if frame < 0 || frame >= r.framesTotal {
+		return fmt.Errorf("error going to frame %d: %w", frame, ErrFrameOutOfRange)
+	}
+	if err := chromedp.Run(r.ctx,
+		chromedp.Evaluate(fmt.Sprintf("anim.goToAndStop(%d, true)", frame), nil),
+	); err != nil {
+		return err
+	}
+	r.framesDone = frame + 1
+	return nil
+}
+
 // RenderFrame renders current frame as PNG and writes the resulting
 // bytes to the provided frame buffer.
 func (r *Renderer) RenderFrame(frameBuf *[]byte) error {
@@ -70,6 +116,57 @@ func (r *Renderer) RenderFrame(frameBuf *[]byte) error {
 		chromedp.CaptureScreenshot(frameBuf))
 }
 
+// SetCache makes [Renderer.RenderFrameAs] return frames kept in cache
+// instead of capturing them again, captured frames are added to it. Key
+// must identify the animation data and the options affecting rendered
+// pixels, the viewport size, format, quality and frame are added to it.
+// A nil cache disables caching.
+func (r *Renderer) SetCache(cache *RenderCache, key string) {
+	r.cache, r.cacheKey = cache, key
+}
+
+// RenderFrameAs renders current frame in the provided format and writes
+// the resulting bytes to the provided frame buffer. Quality in range
+// [0..100] applies to JPEG and WebP, Chrome encodes WebP losslessly at 100.
+func (r *Renderer) RenderFrameAs(frameBuf *[]byte, format ImageFormat, quality int) error {
+	if r.cache == nil {
+		return r.renderFrameAs(frameBuf, format, quality)
+	}
+	key := fmt.Sprintf("%s/%dx%d/%s/%d/%d", r.cacheKey, r.width, r.height, format, quality, r.framesDone-1)
+	if data, ok := r.cache.Get(key); ok {
+		*frameBuf = data
+		return nil
+	}
+	if err := r.renderFrameAs(frameBuf, format, quality); err != nil {
+		return err
+	}
+	if err := r.cache.Put(key, *frameBuf); err != nil {
+		return fmt.Errorf("error caching frame %d: %w", r.framesDone-1, err)
+	}
+	return nil
+}
+
+func (r *Renderer) renderFrameAs(frameBuf *[]byte, format ImageFormat, quality int) error {
+	switch format {
+	case "", FormatPNG:
+		return r.RenderFrame(frameBuf)
+	case FormatSVG:
+		var svg string
+		err := r.RenderFrameSVG(&svg)
+		*frameBuf = []byte(svg)
+		return err
+	}
+	return chromedp.Run(r.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		*frameBuf, err = page.CaptureScreenshot().
+			WithFormat(page.CaptureScreenshotFormat(format)).
+			WithQuality(int64(quality)).
+			WithFromSurface(true).
+			Do(ctx)
+		return err
+	}))
+}
+
 // RenderFrameSVG renders current frame as SVG and writes the resulting
 // SVG string to the provided frame buffer.
 func (r *Renderer) RenderFrameSVG(frameBuf *string) error {
@@ -77,17 +174,49 @@ func (r *Renderer) RenderFrameSVG(frameBuf *string) error {
 		chromedp.OuterHTML("svg", frameBuf, chromedp.ByQuery))
 }
 
-// func (r *Renderer) RenderAll(frames *[]*[]byte) error {
-// 	return chromedp.Run(r.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
-// 		var err error
-// 		for i, frame := range *frames {
-// 			if err = chromedp.Screenshot("#lottie", frame, chromedp.ByQuery).Do(ctx); err != nil {
-// 				return err
-// 			}
-// 			if err = chromedp.Evaluate(fmt.Sprintf("anim.goToAndStop(%d, true)", i), nil).Do(ctx); err != nil {
-// 				return err
-// 			}
-// 		}
-// 		return nil
-// 	}))
-// }
+// RenderAll renders every frame left into sink and closes it. Frames
+// are rendered as PNG unless the sink implements [FrameFormatter].
+// The sink is opened with the animation metadata if it implements
+// [Describer], otherwise only the size and frame count are known.
+//
+// Example:
+//
+//	sink, err := golottie.NewSink("dir", "render/%04d.png")
+//	...
+//	err = renderer.RenderAll(sink)
+func (r *Renderer) RenderAll(sink Sink) error {
+	frames := make([]int, 0, maxInt(r.framesTotal-r.framesDone, 0))
+	for n := r.framesDone; n < r.framesTotal; n++ {
+		frames = append(frames, n)
+	}
+	return r.RenderFramesTo(sink, frames)
+}
+
+// RenderFramesTo renders the frames with the provided numbers into sink
+// and closes it, such as the frames missing from an interrupted render.
+// See [Renderer.RenderAll].
+func (r *Renderer) RenderFramesTo(sink Sink, frames []int) error {
+	if err := sink.Open(r.meta); err != nil {
+		return err
+	}
+	format, quality := FormatPNG, 0
+	if f, ok := sink.(FrameFormatter); ok {
+		format, quality = f.FrameFormat()
+	}
+	for _, n := range frames {
+		if err := r.GoToFrame(n); err != nil {
+			sink.Close()
+			return err
+		}
+		var buf []byte
+		if err := r.RenderFrameAs(&buf, format, quality); err != nil {
+			sink.Close()
+			return err
+		}
+		if err := sink.WriteFrame(Frame{Num: n, Data: buf}); err != nil {
+			sink.Close()
+			return err
+		}
+	}
+	return sink.Close()
+}
@@ -1,8 +1,10 @@
 package golottie
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
+	"regexp"
 	"testing"
 	"time"
 
@@ -202,3 +204,83 @@ func Test_InvalidAnimation(t *testing.T) {
 	renderer := New(ctx)
 	assert.Error(t, renderer.SetAnimation(&noURL{}))
 }
+
+func Test_RenderPDF(t *testing.T) {
+	p, c := context.WithTimeout(context.Background(), 5*time.Second)
+	defer c()
+	ctx, cancel := NewContext(p)
+	defer cancel()
+	renderer := New(ctx)
+	err := renderer.SetAnimation(&okAnimation)
+	if !assert.NoError(t, err) {
+		return
+	}
+	//nolint:all // Animation.close() doesn't return an error to check
+	defer okAnimation.Close()
+
+	var buf []byte
+	assert.NoError(t, renderer.RenderFramePDF(&buf))
+	assert.True(t, bytes.HasPrefix(buf, []byte("%PDF")))
+
+	data, err := renderer.RenderPDF([]int{0, 10, 20})
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF")))
+	assert.Len(t, regexp.MustCompile(`/Type\s*/Page[^s]`).FindAll(data, -1), 3)
+
+	_, err = renderer.RenderPDF(nil)
+	assert.ErrorIs(t, err, ErrNoFrames)
+}
+
+func Test_SVGRasterizer(t *testing.T) {
+	p, c := context.WithTimeout(context.Background(), 5*time.Second)
+	defer c()
+	ctx, cancel := NewContext(p)
+	defer cancel()
+	renderer := New(ctx)
+	err := renderer.SetAnimation(&okAnimation)
+	if !assert.NoError(t, err) {
+		return
+	}
+	//nolint:all // Animation.close() doesn't return an error to check
+	defer okAnimation.Close()
+	assert.True(t, renderer.NextFrame())
+	var svg string
+	assert.NoError(t, renderer.RenderFrameSVG(&svg))
+
+	rasterizer, err := NewSVGRasterizer(ctx, 120, 80)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var buf []byte
+	assert.NoError(t, rasterizer.Rasterize(svg, &buf))
+	img, err := decodeNRGBA(buf)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 120, img.Rect.Dx())
+		assert.Equal(t, 80, img.Rect.Dy())
+	}
+	assert.NoError(t, rasterizer.Close())
+}
+
+func Test_RenderAll(t *testing.T) {
+	p, c := context.WithTimeout(context.Background(), 5*time.Second)
+	defer c()
+	ctx, cancel := NewContext(p)
+	defer cancel()
+	renderer := New(ctx)
+	err := renderer.SetAnimation(&okAnimation)
+	if !assert.NoError(t, err) {
+		return
+	}
+	//nolint:all // Animation.close() doesn't return an error to check
+	defer okAnimation.Close()
+	assert.NoError(t, renderer.GoToFrame(60))
+
+	sink := NewMemorySink()
+	assert.NoError(t, renderer.RenderAll(sink))
+	frames := sink.Frames()
+	if assert.Len(t, frames, 7) {
+		assert.Equal(t, 61, frames[0].Num)
+		assert.True(t, bytes.HasPrefix(frames[0].Data, []byte("\x89PNG")))
+	}
+	assert.Equal(t, Metadata{Width: 600, Height: 600, FramesTotal: 68}, sink.Metadata())
+}
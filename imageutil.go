@@ -0,0 +1,168 @@
+package golottie
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+)
+
+// decodeNRGBA decodes PNG encoded frame into non-premultiplied image
+// with bounds starting at the origin.
+func decodeNRGBA(data []byte) (*image.NRGBA, error) {
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	img := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(img, img.Rect, src, b.Min, draw.Src)
+	return img, nil
+}
+
+// resizeImage scales src to w x h averaging the source pixels covered
+// by every destination pixel, which keeps downscaled frames smooth.
+func resizeImage(src *image.NRGBA, w, h int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	sb := src.Bounds()
+	sx, sy := float64(sb.Dx())/float64(w), float64(sb.Dy())/float64(h)
+	for y := 0; y < h; y++ {
+		y0 := sb.Min.Y + int(float64(y)*sy)
+		y1 := maxInt(y0+1, sb.Min.Y+int(float64(y+1)*sy))
+		for x := 0; x < w; x++ {
+			x0 := sb.Min.X + int(float64(x)*sx)
+			x1 := maxInt(x0+1, sb.Min.X+int(float64(x+1)*sx))
+			// average premultiplied colors so transparent pixels don't bleed
+			var r, g, b, a, n int
+			for yy := y0; yy < y1 && yy < sb.Max.Y; yy++ {
+				for xx := x0; xx < x1 && xx < sb.Max.X; xx++ {
+					p := src.Pix[src.PixOffset(xx, yy):]
+					pa := int(p[3])
+					r, g, b, a = r+int(p[0])*pa, g+int(p[1])*pa, b+int(p[2])*pa, a+pa
+					n++
+				}
+			}
+			if n == 0 || a == 0 {
+				continue
+			}
+			d := dst.Pix[dst.PixOffset(x, y):]
+			d[0], d[1], d[2], d[3] = uint8(r/a), uint8(g/a), uint8(b/a), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// fitSize returns w x h scaled to fit into width keeping the aspect ratio.
+func fitSize(w, h, width int) (int, int) {
+	if w <= 0 || h <= 0 {
+		return width, width
+	}
+	return width, maxInt(1, h*width/w)
+}
+
+// glyphWidth and glyphHeight are dimensions of the built-in font glyphs,
+// glyphs are advanced by an extra column.
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// glyphs is a 5x7 bitmap font, every byte is a row with the most
+// significant of the five bits being the leftmost pixel.
+var glyphs = map[rune][glyphHeight]byte{
+	'0':  {0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e},
+	'1':  {0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'2':  {0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f},
+	'3':  {0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e},
+	'4':  {0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02},
+	'5':  {0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e},
+	'6':  {0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e},
+	'7':  {0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8':  {0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e},
+	'9':  {0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c},
+	'A':  {0x0e, 0x11, 0x11, 0x11, 0x1f, 0x11, 0x11},
+	'B':  {0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e},
+	'C':  {0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e},
+	'D':  {0x1c, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1c},
+	'E':  {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f},
+	'F':  {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10},
+	'G':  {0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f},
+	'H':  {0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'I':  {0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'J':  {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c},
+	'K':  {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L':  {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f},
+	'M':  {0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N':  {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O':  {0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'P':  {0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10},
+	'Q':  {0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d},
+	'R':  {0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11},
+	'S':  {0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e},
+	'T':  {0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'V':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04},
+	'W':  {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a},
+	'X':  {0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11},
+	'Y':  {0x11, 0x11, 0x11, 0x0a, 0x04, 0x04, 0x04},
+	'Z':  {0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f},
+	' ':  {},
+	'.':  {0, 0, 0, 0, 0, 0x0c, 0x0c},
+	',':  {0, 0, 0, 0, 0x0c, 0x04, 0x08},
+	':':  {0, 0x0c, 0x0c, 0, 0x0c, 0x0c, 0},
+	'#':  {0x0a, 0x0a, 0x1f, 0x0a, 0x1f, 0x0a, 0x0a},
+	'-':  {0, 0, 0, 0x1f, 0, 0, 0},
+	'_':  {0, 0, 0, 0, 0, 0, 0x1f},
+	'/':  {0, 0x01, 0x02, 0x04, 0x08, 0x10, 0},
+	'(':  {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02},
+	')':  {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	'?':  {0x0e, 0x11, 0x01, 0x02, 0x04, 0, 0x04},
+	'!':  {0x04, 0x04, 0x04, 0x04, 0x04, 0, 0x04},
+	'%':  {0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03},
+	'+':  {0, 0x04, 0x04, 0x1f, 0x04, 0x04, 0},
+	'=':  {0, 0, 0x1f, 0, 0x1f, 0, 0},
+	'\'': {0x0c, 0x04, 0x08, 0, 0, 0, 0},
+}
+
+// textWidth returns the width of text drawn with [drawText].
+func textWidth(text string, scale int) int {
+	return len([]rune(text)) * (glyphWidth + 1) * scale
+}
+
+// truncateText shortens text to fit into width.
+func truncateText(text string, scale, width int) string {
+	runes := []rune(text)
+	max := width / ((glyphWidth + 1) * scale)
+	if len(runes) <= max {
+		return text
+	}
+	if max < 1 {
+		return ""
+	}
+	return string(runes[:max-1]) + "."
+}
+
+// drawText draws text with the top-left corner at x, y using the
+// built-in font scaled by scale. Letters are drawn uppercase and
+// characters missing from the font as '?'.
+func drawText(dst draw.Image, x, y int, text string, c color.Color, scale int) {
+	fill := image.NewUniform(c)
+	for _, r := range strings.ToUpper(text) {
+		glyph, ok := glyphs[r]
+		if !ok {
+			glyph = glyphs['?']
+		}
+		for row, bits := range glyph {
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(0x10>>col) == 0 {
+					continue
+				}
+				px := image.Rect(x+col*scale, y+row*scale, x+(col+1)*scale, y+(row+1)*scale)
+				draw.Draw(dst, px, fill, image.Point{}, draw.Over)
+			}
+		}
+		x += (glyphWidth + 1) * scale
+	}
+}
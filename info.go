@@ -0,0 +1,253 @@
+package golottie
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Info contains animation metadata collected by [AnimationData.Info].
+type Info struct {
+	Name        string      `json:"name"`
+	Version     string      `json:"version"`
+	Width       float64     `json:"width"`
+	Height      float64     `json:"height"`
+	FrameRate   float64     `json:"frameRate"`
+	InPoint     float64     `json:"inPoint"`
+	OutPoint    float64     `json:"outPoint"`
+	Duration    float64     `json:"duration"`
+	ThreeD      bool        `json:"threeD"`
+	Expressions int         `json:"expressions"`
+	Layers      []LayerInfo `json:"layers"`
+	Assets      []AssetInfo `json:"assets"`
+	Fonts       []Font      `json:"fonts"`
+	Markers     []Marker    `json:"markers"`
+}
+
+// LayerInfo describes a layer. Layers of precomp layers are
+// listed as the layer children.
+type LayerInfo struct {
+	Index       int         `json:"index"`
+	Name        string      `json:"name"`
+	Type        LayerType   `json:"type"`
+	Parent      int         `json:"parent,omitempty"`
+	InPoint     float64     `json:"inPoint"`
+	OutPoint    float64     `json:"outPoint"`
+	StartTime   float64     `json:"startTime"`
+	Hidden      bool        `json:"hidden,omitempty"`
+	ThreeD      bool        `json:"threeD,omitempty"`
+	RefID       string      `json:"refId,omitempty"`
+	Expressions int         `json:"expressions,omitempty"`
+	Children    []LayerInfo `json:"children,omitempty"`
+}
+
+// AssetInfo describes an image or a precomp asset.
+type AssetInfo struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name,omitempty"`
+	Precomp  bool    `json:"precomp"`
+	Width    float64 `json:"width,omitempty"`
+	Height   float64 `json:"height,omitempty"`
+	Path     string  `json:"path,omitempty"`
+	Embedded bool    `json:"embedded,omitempty"`
+	Layers   int     `json:"layers,omitempty"`
+}
+
+// Info parses the initial animation data and returns its metadata.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("animation.json")
+//	info, err := golottie.NewAnimation(data).Info()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	info.WriteText(os.Stdout)
+func (a *AnimationData) Info() (*Info, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	c, err := ParseComposition(a.data)
+	if err != nil {
+		return nil, err
+	}
+	return NewInfo(c), nil
+}
+
+// NewInfo collects metadata of the parsed composition.
+func NewInfo(c *Composition) *Info {
+	info := &Info{
+		Name:      c.Name,
+		Version:   c.Version,
+		Width:     c.Width,
+		Height:    c.Height,
+		FrameRate: c.FrameRate,
+		InPoint:   c.InPoint,
+		OutPoint:  c.OutPoint,
+		Duration:  c.Duration(),
+		ThreeD:    c.ThreeD != 0,
+		Fonts:     c.Fonts.List,
+		Markers:   c.Markers,
+	}
+	info.Layers = layerTree(c, c.Layers, map[string]bool{})
+	info.Expressions = layerExpressions(c, c.Layers, map[string]bool{})
+	for _, asset := range c.Assets {
+		info.Assets = append(info.Assets, AssetInfo{
+			ID:       asset.ID,
+			Name:     asset.Name,
+			Precomp:  asset.IsPrecomp(),
+			Width:    asset.Width,
+			Height:   asset.Height,
+			Path:     assetPath(asset),
+			Embedded: asset.IsEmbedded(),
+			Layers:   len(asset.Layers),
+		})
+	}
+	return info
+}
+
+// WriteText writes human-readable animation metadata to w.
+func (i *Info) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+	fmt.Fprintf(tw, "Name:\t%s\n", i.Name)
+	fmt.Fprintf(tw, "Bodymovin:\t%s\n", i.Version)
+	fmt.Fprintf(tw, "Size:\t%gx%g\n", i.Width, i.Height)
+	fmt.Fprintf(tw, "Frame rate:\t%s fps\n", round(i.FrameRate))
+	fmt.Fprintf(tw, "Frames:\t%s - %s\n", round(i.InPoint), round(i.OutPoint))
+	fmt.Fprintf(tw, "Duration:\t%.3fs\n", i.Duration)
+	fmt.Fprintf(tw, "3D:\t%t\n", i.ThreeD)
+	fmt.Fprintf(tw, "Expressions:\t%d\n", i.Expressions)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nLayers (%d):\n", len(flattenLayers(i.Layers)))
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	writeLayerTree(tw, i.Layers, 1)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(i.Assets) > 0 {
+		fmt.Fprintf(w, "\nAssets (%d):\n", len(i.Assets))
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, a := range i.Assets {
+			if a.Precomp {
+				fmt.Fprintf(tw, "  %s\tprecomp\t%d layers\n", a.ID, a.Layers)
+				continue
+			}
+			source := a.Path
+			if a.Embedded {
+				source = "embedded"
+			}
+			fmt.Fprintf(tw, "  %s\timage\t%gx%g\t%s\n", a.ID, a.Width, a.Height, source)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(i.Fonts) > 0 {
+		fmt.Fprintf(w, "\nFonts (%d):\n", len(i.Fonts))
+		for _, f := range i.Fonts {
+			fmt.Fprintf(w, "  %s (%s %s)\n", f.Name, f.Family, f.Style)
+		}
+	}
+	if len(i.Markers) > 0 {
+		fmt.Fprintf(w, "\nMarkers (%d):\n", len(i.Markers))
+		for _, m := range i.Markers {
+			fmt.Fprintf(w, "  %s: %s - %s\n", m.Comment, round(m.Time), round(m.Time+m.Duration))
+		}
+	}
+	return nil
+}
+
+// layerTree builds layer info recursively descending into precomps.
+// Visited precomps are tracked to guard against cyclic references.
+func layerTree(c *Composition, layers []Layer, visited map[string]bool) []LayerInfo {
+	var tree []LayerInfo
+	for _, layer := range layers {
+		info := LayerInfo{
+			Index:       layer.Index,
+			Name:        layer.Name,
+			Type:        layer.Type,
+			Parent:      layer.Parent,
+			InPoint:     layer.InPoint,
+			OutPoint:    layer.OutPoint,
+			StartTime:   layer.StartTime,
+			Hidden:      layer.Hidden,
+			ThreeD:      layer.ThreeD != 0,
+			RefID:       layer.RefID,
+			Expressions: countExpressions(layer.Raw),
+		}
+		if precomp := c.Precomp(layer.RefID); layer.Type == LayerPrecomp && precomp != nil && !visited[precomp.ID] {
+			visited[precomp.ID] = true
+			info.Children = layerTree(c, precomp.Layers, visited)
+			delete(visited, precomp.ID)
+		}
+		tree = append(tree, info)
+	}
+	return tree
+}
+
+// layerExpressions counts expressions of layers and the precomps they
+// reference. Each precomp is counted once, however many layers use it.
+func layerExpressions(c *Composition, layers []Layer, counted map[string]bool) int {
+	var n int
+	for _, layer := range layers {
+		n += countExpressions(layer.Raw)
+		if precomp := c.Precomp(layer.RefID); layer.Type == LayerPrecomp && precomp != nil && !counted[precomp.ID] {
+			counted[precomp.ID] = true
+			n += layerExpressions(c, precomp.Layers, counted)
+		}
+	}
+	return n
+}
+
+// flattenLayers returns all layers of the tree in depth-first order.
+func flattenLayers(tree []LayerInfo) []LayerInfo {
+	var layers []LayerInfo
+	for _, layer := range tree {
+		layers = append(layers, layer)
+		layers = append(layers, flattenLayers(layer.Children)...)
+	}
+	return layers
+}
+
+func writeLayerTree(w io.Writer, tree []LayerInfo, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, l := range tree {
+		var flags []string
+		if l.Parent != 0 {
+			flags = append(flags, fmt.Sprintf("parent %d", l.Parent))
+		}
+		if l.Hidden {
+			flags = append(flags, "hidden")
+		}
+		if l.ThreeD {
+			flags = append(flags, "3D")
+		}
+		if l.RefID != "" {
+			flags = append(flags, "ref "+l.RefID)
+		}
+		if l.Expressions > 0 {
+			flags = append(flags, fmt.Sprintf("%d expressions", l.Expressions))
+		}
+		fmt.Fprintf(w, "%s%d %s\t%s\t%s - %s\t%s\n", indent, l.Index, l.Name, l.Type, round(l.InPoint), round(l.OutPoint), strings.Join(flags, ", "))
+		writeLayerTree(w, l.Children, depth+1)
+	}
+}
+
+// round formats v rounded to 3 decimal places.
+func round(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+func assetPath(a Asset) string {
+	if a.IsEmbedded() {
+		return ""
+	}
+	return a.Dir + a.Path
+}
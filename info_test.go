@@ -0,0 +1,74 @@
+package golottie
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var exprAnimData = []byte(`{"v":"5.7.4","nm":"expr","fr":60,"ip":0,"op":120,"w":512,"h":256,"ddd":0,
+"fonts":{"list":[{"fName":"Roboto-Bold","fFamily":"Roboto","fStyle":"Bold","origin":0}]},
+"markers":[{"cm":"intro","tm":0,"dr":30}],
+"assets":[{"id":"image_0","w":64,"h":64,"u":"images/","p":"img_0.png","e":0}],
+"layers":[
+{"ind":1,"ty":3,"nm":"Null","ip":0,"op":120,"st":0,"ks":{"r":{"a":0,"k":0,"x":"wiggle(1, 10)"}}},
+{"ind":2,"ty":4,"nm":"Shape","parent":1,"hd":true,"ip":10,"op":60,"st":0,"ks":{"p":{"s":true,"x":{"a":0,"k":5},"y":{"a":0,"k":5}}}},
+{"ind":3,"ty":2,"nm":"Image","refId":"image_0","ip":0,"op":120,"st":0,"ks":{}}
+]}`)
+
+func Test_Info(t *testing.T) {
+	t.Run("Precomp_tree", func(t *testing.T) {
+		info, err := NewAnimation(animData).Info()
+		assert.NoError(t, err)
+		assert.Equal(t, "main", info.Name)
+		assert.Equal(t, "5.10.0", info.Version)
+		assert.Equal(t, 600.0, info.Width)
+		assert.InDelta(t, 68/29.97, info.Duration, 0.001)
+		assert.Len(t, info.Layers, 1)
+		assert.Equal(t, LayerPrecomp, info.Layers[0].Type)
+		assert.Len(t, info.Layers[0].Children, 2)
+		assert.Equal(t, LayerSolid, info.Layers[0].Children[0].Type)
+		assert.True(t, info.Assets[0].Precomp)
+	})
+	t.Run("Expressions", func(t *testing.T) {
+		info, err := NewAnimation(exprAnimData).Info()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, info.Expressions)
+		assert.Equal(t, 1, info.Layers[0].Expressions)
+		assert.True(t, info.Layers[1].Hidden)
+		assert.Equal(t, 1, info.Layers[1].Parent)
+		assert.Equal(t, "images/img_0.png", info.Assets[0].Path)
+		assert.Len(t, info.Fonts, 1)
+		assert.Len(t, info.Markers, 1)
+
+		var buf bytes.Buffer
+		assert.NoError(t, info.WriteText(&buf))
+		for _, s := range []string{"512x256", "Shape", "hidden", "1 expressions", "intro", "Roboto-Bold"} {
+			assert.Contains(t, buf.String(), s)
+		}
+
+		data, err := json.Marshal(info)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"type":"shape"`)
+	})
+	t.Run("Shared_precomp", func(t *testing.T) {
+		data := []byte(`{"v":"5.7.0","fr":30,"w":10,"h":10,"ip":0,"op":5,
+"assets":[{"id":"comp","layers":[{"ind":1,"ty":3,"nm":"inner","ip":0,"op":5,"st":0,"ks":{"r":{"a":0,"k":0,"x":"time"}}}]}],
+"layers":[{"ind":1,"ty":0,"nm":"a","refId":"comp","ip":0,"op":5,"st":0,"ks":{}},{"ind":2,"ty":0,"nm":"b","refId":"comp","ip":0,"op":5,"st":0,"ks":{}}]}`)
+		info, err := NewAnimation(data).Info()
+		assert.NoError(t, err)
+		assert.Len(t, info.Layers[0].Children, 1)
+		assert.Len(t, info.Layers[1].Children, 1)
+		assert.Equal(t, 1, info.Expressions)
+	})
+	t.Run("Nil_animation", func(t *testing.T) {
+		_, err := NewAnimation(nil).Info()
+		assert.ErrorIs(t, err, ErrNilAnimationData)
+	})
+	t.Run("Bad_animation", func(t *testing.T) {
+		_, err := NewAnimation([]byte("(╯°□°)╯")).Info()
+		assert.Error(t, err)
+	})
+}
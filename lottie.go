@@ -0,0 +1,217 @@
+package golottie
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// LayerType is a Lottie layer type as specified by the "ty" field.
+type LayerType int
+
+const (
+	LayerPrecomp LayerType = iota
+	LayerSolid
+	LayerImage
+	LayerNull
+	LayerShape
+	LayerText
+	LayerAudio
+	LayerVideoPlaceholder
+	LayerImageSequence
+	LayerVideo
+	LayerImagePlaceholder
+	LayerGuide
+	LayerAdjustment
+	LayerCamera
+	LayerLight
+	LayerData
+)
+
+var layerTypeNames = [...]string{
+	"precomp", "solid", "image", "null", "shape", "text", "audio",
+	"video placeholder", "image sequence", "video", "image placeholder",
+	"guide", "adjustment", "camera", "light", "data",
+}
+
+// String returns a human-readable layer type name.
+func (t LayerType) String() string {
+	if t >= 0 && int(t) < len(layerTypeNames) {
+		return layerTypeNames[t]
+	}
+	return fmt.Sprintf("unknown (%d)", int(t))
+}
+
+// MarshalText implements [encoding.TextMarshaler] so layer types
+// are written as names in JSON reports.
+func (t LayerType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// Composition is a partial representation of Lottie animation data
+// used to inspect animations without rendering them.
+type Composition struct {
+	Version   string   `json:"v"`
+	Name      string   `json:"nm"`
+	FrameRate float64  `json:"fr"`
+	InPoint   float64  `json:"ip"`
+	OutPoint  float64  `json:"op"`
+	Width     float64  `json:"w"`
+	Height    float64  `json:"h"`
+	ThreeD    int      `json:"ddd"`
+	Layers    []Layer  `json:"layers"`
+	Assets    []Asset  `json:"assets"`
+	Fonts     FontList `json:"fonts"`
+	Markers   []Marker `json:"markers"`
+}
+
+// Layer is a partial representation of a Lottie layer.
+// Properties which aren't needed for inspection are kept as raw JSON.
+type Layer struct {
+	Index       int               `json:"ind"`
+	Type        LayerType         `json:"ty"`
+	Name        string            `json:"nm"`
+	Parent      int               `json:"parent"`
+	InPoint     float64           `json:"ip"`
+	OutPoint    float64           `json:"op"`
+	StartTime   float64           `json:"st"`
+	Stretch     float64           `json:"sr"`
+	ThreeD      int               `json:"ddd"`
+	Hidden      bool              `json:"hd"`
+	RefID       string            `json:"refId"`
+	Width       float64           `json:"w"`
+	Height      float64           `json:"h"`
+	MatteMode   int               `json:"tt"`
+	MatteTarget int               `json:"td"`
+	HasMask     bool              `json:"hasMask"`
+	BlendMode   int               `json:"bm"`
+	Masks       []json.RawMessage `json:"masksProperties"`
+	Effects     []json.RawMessage `json:"ef"`
+	Shapes      []json.RawMessage `json:"shapes"`
+
+	// Raw contains the whole layer JSON.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements [json.Unmarshaler] keeping the raw layer data.
+func (l *Layer) UnmarshalJSON(data []byte) error {
+	type layer Layer
+	if err := json.Unmarshal(data, (*layer)(l)); err != nil {
+		return err
+	}
+	l.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// Asset is a partial representation of a Lottie asset,
+// either an image or a precomp if Layers aren't nil.
+type Asset struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"nm"`
+	Width    float64 `json:"w"`
+	Height   float64 `json:"h"`
+	Path     string  `json:"p"`
+	Dir      string  `json:"u"`
+	Embedded int     `json:"e"`
+	Layers   []Layer `json:"layers"`
+}
+
+// IsPrecomp reports whether the asset is a precomp.
+func (a *Asset) IsPrecomp() bool {
+	return a.Layers != nil
+}
+
+// IsEmbedded reports whether the image asset data is embedded as a data URI.
+func (a *Asset) IsEmbedded() bool {
+	return a.Embedded != 0 || strings.HasPrefix(a.Path, "data:")
+}
+
+// FontList is a list of fonts used by text layers.
+type FontList struct {
+	List []Font `json:"list"`
+}
+
+// Font is a font definition used by text layers.
+type Font struct {
+	Name   string `json:"fName"`
+	Family string `json:"fFamily"`
+	Style  string `json:"fStyle"`
+	Path   string `json:"fPath,omitempty"`
+	Origin int    `json:"origin"`
+}
+
+// Marker is a named composition time range.
+type Marker struct {
+	Comment  string  `json:"cm"`
+	Time     float64 `json:"tm"`
+	Duration float64 `json:"dr"`
+}
+
+// ParseComposition parses Lottie animation data.
+func ParseComposition(data []byte) (*Composition, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("error parsing animation: %w", ErrNilAnimationData)
+	}
+	var c Composition
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("error parsing animation: %w", err)
+	}
+	return &c, nil
+}
+
+// Precomp returns the precomp asset with the provided id or nil.
+func (c *Composition) Precomp(id string) *Asset {
+	for i := range c.Assets {
+		if c.Assets[i].ID == id && c.Assets[i].IsPrecomp() {
+			return &c.Assets[i]
+		}
+	}
+	return nil
+}
+
+// Duration returns the animation duration in seconds.
+func (c *Composition) Duration() float64 {
+	if c.FrameRate <= 0 {
+		return 0
+	}
+	return (c.OutPoint - c.InPoint) / c.FrameRate
+}
+
+// countExpressions counts animated properties driven by expressions
+// in the raw JSON value.
+func countExpressions(data json.RawMessage) int {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return 0
+	}
+	return countExpressionsIn(v)
+}
+
+// countExpressionsIn counts animated properties driven by expressions
+// in the decoded JSON value.
+func countExpressionsIn(v interface{}) int {
+	count := 0
+	walkJSON(v, func(m map[string]interface{}) {
+		if x, ok := m["x"].(string); ok && x != "" {
+			if _, ok := m["k"]; ok {
+				count++
+			}
+		}
+	})
+	return count
+}
+
+// walkJSON calls fn for every object in the decoded JSON value.
+func walkJSON(v interface{}, fn func(map[string]interface{})) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		fn(v)
+		for _, child := range v {
+			walkJSON(child, fn)
+		}
+	case []interface{}:
+		for _, child := range v {
+			walkJSON(child, fn)
+		}
+	}
+}
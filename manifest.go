@@ -0,0 +1,265 @@
+package golottie
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+)
+
+// RenderManifest describes rendered frames for downstream tools.
+type RenderManifest struct {
+	// Golottie and LottieWeb are the versions the frames were rendered with.
+	Golottie  string `json:"golottie"`
+	LottieWeb string `json:"lottieWeb"`
+	// Source is the file name of the animation, SourceHash is the SHA-256
+	// of its data.
+	Source     string `json:"source,omitempty"`
+	SourceHash string `json:"sourceHash,omitempty"`
+	// OptionsHash identifies the options the frames were rendered with.
+	OptionsHash string  `json:"optionsHash,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	Scale       float64 `json:"scale"`
+	FrameRate   float64 `json:"fps"`
+	FrameCount  int     `json:"frameCount"`
+	// Frames are sorted by frame number.
+	Frames  []ManifestFrame  `json:"frames"`
+	Markers []ManifestMarker `json:"markers,omitempty"`
+}
+
+// ManifestFrame describes a rendered frame, times are in seconds.
+type ManifestFrame struct {
+	// Frame is the zero based frame number, Source is the animation
+	// frame counted from the in point.
+	Frame    int     `json:"frame"`
+	Source   int     `json:"source"`
+	File     string  `json:"file,omitempty"`
+	Time     float64 `json:"time"`
+	Duration float64 `json:"duration"`
+	// Hash is the SHA-256 of the frame data.
+	Hash string `json:"hash"`
+}
+
+// ManifestMarker maps a marker to the zero based frame range it covers,
+// End is inclusive.
+type ManifestMarker struct {
+	Name  string `json:"name"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// ManifestOptions configures [ManifestSink].
+type ManifestOptions struct {
+	// Name returns the file name of a frame as the wrapped sink names it.
+	Name FrameNamer
+	// Source is the file name of the animation and SourceData its data.
+	Source     string
+	SourceData []byte
+	// Info provides the markers and the in point of the animation.
+	Info *Info
+	// OptionsHash identifies the render options, see [RenderManifest.VerifyFrames].
+	OptionsHash string
+	// Width and Height are the rendered size, the size of the
+	// animation is used if they are zero.
+	Width  int
+	Height int
+	// Frames are kept from a previous render, such as the frames
+	// returned by [RenderManifest.VerifyFrames] when resuming it.
+	Frames []ManifestFrame
+	// LottieWeb is the lottie-web version the frames were rendered with,
+	// such as [Renderer.LottieWebVersion] reports. [LottieWebVersion] of
+	// the default template is recorded if it's empty.
+	LottieWeb string
+	// Checkpoint rewrites the manifest every Checkpoint frames, so an
+	// interrupted render can be resumed. Zero writes it on Close only.
+	Checkpoint int
+}
+
+// ManifestSink passes frames to the wrapped sink and writes
+// [RenderManifest] describing them once the sink is closed. WriteFrame
+// is safe for concurrent use if the wrapped sink's one is.
+//
+// Example:
+//
+//	namer := golottie.NewFrameNamer(tmpl, info, golottie.OutputVars{Frame: 1})
+//	sink := golottie.NewManifestSink(golottie.NewDirSinkFunc(namer), func() (io.WriteCloser, error) {
+//		return os.Create("render/manifest.json")
+//	}, golottie.ManifestOptions{Name: namer, Info: info, SourceData: data})
+//	err := renderer.RenderAll(sink)
+type ManifestSink struct {
+	Sink
+	create   func() (io.WriteCloser, error)
+	opts     ManifestOptions
+	mu       sync.Mutex
+	manifest RenderManifest
+	written  int
+	// writeMu serializes manifest writes
+	writeMu sync.Mutex
+}
+
+// NewManifestSink returns sink wrapping sink, create opens the manifest
+// file on Close.
+func NewManifestSink(sink Sink, create func() (io.WriteCloser, error), opts ManifestOptions) *ManifestSink {
+	return &ManifestSink{Sink: sink, create: create, opts: opts}
+}
+
+// FrameFormat returns the frame format of the wrapped sink.
+func (s *ManifestSink) FrameFormat() (ImageFormat, int) {
+	if f, ok := s.Sink.(FrameFormatter); ok {
+		return f.FrameFormat()
+	}
+	return FormatPNG, 0
+}
+
+// Open opens the wrapped sink and starts the manifest.
+func (s *ManifestSink) Open(meta Metadata) error {
+	m := RenderManifest{
+		Golottie:    Version,
+		LottieWeb:   s.opts.LottieWeb,
+		Source:      s.opts.Source,
+		OptionsHash: s.opts.OptionsHash,
+		Name:        meta.Name,
+		Width:       s.opts.Width,
+		Height:      s.opts.Height,
+		Scale:       1,
+		FrameRate:   meta.FrameRate,
+		FrameCount:  meta.FramesTotal,
+		Frames:      append([]ManifestFrame(nil), s.opts.Frames...),
+	}
+	if m.LottieWeb == "" {
+		m.LottieWeb = LottieWebVersion
+	}
+	if s.opts.SourceData != nil {
+		m.SourceHash = HashData(s.opts.SourceData)
+	}
+	if m.Width == 0 || m.Height == 0 {
+		m.Width, m.Height = meta.Width, meta.Height
+	}
+	if meta.Width > 0 {
+		m.Scale = float64(m.Width) / float64(meta.Width)
+	}
+	if info := s.opts.Info; info != nil {
+		for _, marker := range info.Markers {
+			start := int(marker.Time - info.InPoint)
+			m.Markers = append(m.Markers, ManifestMarker{
+				Name:  marker.Comment,
+				Start: start,
+				End:   start + int(math.Max(marker.Duration, 1)) - 1,
+			})
+		}
+	}
+	s.mu.Lock()
+	s.manifest, s.written = m, 0
+	s.mu.Unlock()
+	return s.Sink.Open(meta)
+}
+
+// WriteFrame passes frame to the wrapped sink and records it.
+func (s *ManifestSink) WriteFrame(f Frame) error {
+	if err := s.Sink.WriteFrame(f); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.manifest.Frames = append(s.manifest.Frames, s.frame(f))
+	s.written++
+	checkpoint := s.opts.Checkpoint > 0 && s.written%s.opts.Checkpoint == 0
+	s.mu.Unlock()
+	if checkpoint {
+		return s.writeManifest()
+	}
+	return nil
+}
+
+func (s *ManifestSink) frame(f Frame) ManifestFrame {
+	frame := ManifestFrame{Frame: f.Num, Source: f.Num, Hash: HashData(f.Data)}
+	if s.opts.Name != nil {
+		frame.File = s.opts.Name(f)
+	}
+	if s.opts.Info != nil {
+		frame.Source += int(s.opts.Info.InPoint)
+	}
+	if fps := s.manifest.FrameRate; fps > 0 {
+		frame.Time = float64(f.Num) / fps
+		frame.Duration = 1 / fps
+	}
+	return frame
+}
+
+// Close closes the wrapped sink and writes the manifest.
+func (s *ManifestSink) Close() error {
+	if err := s.Sink.Close(); err != nil {
+		return err
+	}
+	return s.writeManifest()
+}
+
+func (s *ManifestSink) writeManifest() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	m := s.Manifest()
+	sort.Slice(m.Frames, func(i, j int) bool { return m.Frames[i].Frame < m.Frames[j].Frame })
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	w, err := s.create()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Manifest returns the manifest of the frames written so far.
+func (s *ManifestSink) Manifest() RenderManifest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.manifest
+	m.Frames = append([]ManifestFrame(nil), s.manifest.Frames...)
+	return m
+}
+
+// ReadRenderManifest decodes the manifest written by [ManifestSink].
+func ReadRenderManifest(r io.Reader) (*RenderManifest, error) {
+	var m RenderManifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("error reading render manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// VerifyFrames returns the frames whose files read with read still have
+// the recorded hash, the rest has to be rendered again. No frames are
+// returned if the manifest was rendered from other source data or with
+// other options, an empty hash matches any.
+func (m *RenderManifest) VerifyFrames(sourceHash, optionsHash string, read func(name string) ([]byte, error)) []ManifestFrame {
+	if (sourceHash != "" && m.SourceHash != sourceHash) || (optionsHash != "" && m.OptionsHash != optionsHash) {
+		return nil
+	}
+	var valid []ManifestFrame
+	for _, frame := range m.Frames {
+		if frame.File == "" {
+			continue
+		}
+		data, err := read(frame.File)
+		if err == nil && HashData(data) == frame.Hash {
+			valid = append(valid, frame)
+		}
+	}
+	return valid
+}
+
+// HashData returns hex encoded SHA-256 of data as recorded in manifests.
+func HashData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
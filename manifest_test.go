@@ -0,0 +1,127 @@
+package golottie
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ManifestSink(t *testing.T) {
+	info, err := NewAnimation(exprAnimData).Info()
+	require.NoError(t, err)
+	tmpl, err := ParseOutputTemplate("{frame:03}.png")
+	require.NoError(t, err)
+	name := NewFrameNamer(tmpl, info, OutputVars{Frame: 1})
+
+	files := make(map[string][]byte)
+	memory := NewMemorySink()
+	sink := NewManifestSink(memory, func() (io.WriteCloser, error) {
+		return memCreate(files)("manifest.json")
+	}, ManifestOptions{
+		Name:       name,
+		Source:     "expr.json",
+		SourceData: exprAnimData,
+		Info:       info,
+		Width:      1024,
+		Height:     512,
+		LottieWeb:  "5.12.2",
+	})
+	require.NoError(t, sink.Open(NewAnimation(exprAnimData).Metadata()))
+	for _, num := range []int{1, 0} {
+		require.NoError(t, sink.WriteFrame(Frame{Num: num, Data: []byte{byte(num)}}))
+	}
+	require.NoError(t, sink.Close())
+	assert.Len(t, memory.Frames(), 2)
+
+	var m RenderManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &m))
+	assert.Equal(t, RenderManifest{
+		Golottie:   Version,
+		LottieWeb:  "5.12.2",
+		Source:     "expr.json",
+		SourceHash: HashData(exprAnimData),
+		Name:       "expr",
+		Width:      1024,
+		Height:     512,
+		Scale:      2,
+		FrameRate:  60,
+		FrameCount: 120,
+		Frames: []ManifestFrame{
+			{Frame: 0, Source: 0, File: "001.png", Time: 0, Duration: 1.0 / 60, Hash: HashData([]byte{0})},
+			{Frame: 1, Source: 1, File: "002.png", Time: 1.0 / 60, Duration: 1.0 / 60, Hash: HashData([]byte{1})},
+		},
+		Markers: []ManifestMarker{{Name: "intro", Start: 0, End: 29}},
+	}, m)
+}
+
+func Test_RenderManifest_VerifyFrames(t *testing.T) {
+	files := map[string][]byte{"1.png": {0}, "2.png": {9}}
+	read := func(name string) ([]byte, error) {
+		if data, ok := files[name]; ok {
+			return data, nil
+		}
+		return nil, os.ErrNotExist
+	}
+	m := &RenderManifest{
+		SourceHash:  "source",
+		OptionsHash: "options",
+		Frames: []ManifestFrame{
+			{Frame: 0, File: "1.png", Hash: HashData([]byte{0})},
+			{Frame: 1, File: "2.png", Hash: HashData([]byte{1})},
+			{Frame: 2, File: "3.png", Hash: HashData([]byte{2})},
+		},
+	}
+	assert.Equal(t, m.Frames[:1], m.VerifyFrames("source", "options", read))
+	assert.Empty(t, m.VerifyFrames("changed", "options", read))
+	assert.Empty(t, m.VerifyFrames("source", "changed", read))
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	read2, err := ReadRenderManifest(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, m, read2)
+	_, err = ReadRenderManifest(strings.NewReader("{"))
+	assert.Error(t, err)
+}
+
+func Test_ManifestSink_resume(t *testing.T) {
+	files := make(map[string][]byte)
+	var writes int
+	done := []ManifestFrame{{Frame: 0, File: "0.png", Hash: HashData([]byte{0})}}
+	sink := NewManifestSink(NewMemorySink(), func() (io.WriteCloser, error) {
+		writes++
+		return memCreate(files)("manifest.json")
+	}, ManifestOptions{
+		Name:        func(f Frame) string { return strconv.Itoa(f.Num) + ".png" },
+		OptionsHash: "options",
+		Frames:      done,
+		Checkpoint:  2,
+	})
+	require.NoError(t, sink.Open(Metadata{Width: 1, Height: 1, FrameRate: 1, FramesTotal: 4}))
+	for _, num := range []int{3, 2} {
+		require.NoError(t, sink.WriteFrame(Frame{Num: num, Data: []byte{byte(num)}}))
+	}
+	// the checkpoint is written before the sink is closed
+	assert.Equal(t, 1, writes)
+	require.NoError(t, sink.WriteFrame(Frame{Num: 1, Data: []byte{1}}))
+	require.NoError(t, sink.Close())
+	assert.Equal(t, 2, writes)
+
+	m, err := ReadRenderManifest(bytes.NewReader(files["manifest.json"]))
+	require.NoError(t, err)
+	assert.Equal(t, "options", m.OptionsHash)
+	assert.Equal(t, LottieWebVersion, m.LottieWeb)
+	require.Len(t, m.Frames, 4)
+	for i, frame := range m.Frames {
+		assert.Equal(t, i, frame.Frame)
+		assert.Equal(t, strconv.Itoa(i)+".png", frame.File)
+		assert.Equal(t, HashData([]byte{byte(i)}), frame.Hash)
+	}
+}
@@ -0,0 +1,410 @@
+package golottie
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/png"
+	"math"
+	"reflect"
+
+	"github.com/ysmood/gson"
+)
+
+// OptimizeOptions controls which optimizations [Optimize] applies.
+type OptimizeOptions struct {
+	// Precision is the number of decimal places numbers are rounded to,
+	// negative value disables rounding.
+	Precision int
+	// RemoveUnusedAssets removes assets not referenced by any layer.
+	RemoveUnusedAssets bool
+	// RemoveHiddenLayers removes hidden layers which aren't used
+	// as parents or mattes.
+	RemoveHiddenLayers bool
+	// CollapseStaticKeyframes replaces animated properties having
+	// the same value in every keyframe with static values.
+	CollapseStaticKeyframes bool
+	// StripEditorFields removes fields used only by the editor. Names and
+	// property indices are kept if the animation uses expressions.
+	StripEditorFields bool
+}
+
+// DefaultOptimizeOptions enables every optimization rounding numbers
+// to 3 decimal places.
+var DefaultOptimizeOptions = OptimizeOptions{
+	Precision:               3,
+	RemoveUnusedAssets:      true,
+	RemoveHiddenLayers:      true,
+	CollapseStaticKeyframes: true,
+	StripEditorFields:       true,
+}
+
+// OptimizeStats contains the results of optimization.
+type OptimizeStats struct {
+	SizeBefore         int `json:"sizeBefore"`
+	SizeAfter          int `json:"sizeAfter"`
+	AssetsRemoved      int `json:"assetsRemoved"`
+	LayersRemoved      int `json:"layersRemoved"`
+	KeyframesCollapsed int `json:"keyframesCollapsed"`
+	FieldsStripped     int `json:"fieldsStripped"`
+}
+
+// Saved returns the relative size reduction.
+func (s *OptimizeStats) Saved() float64 {
+	if s.SizeBefore == 0 {
+		return 0
+	}
+	return 1 - float64(s.SizeAfter)/float64(s.SizeBefore)
+}
+
+// String returns a human-readable optimization summary.
+func (s *OptimizeStats) String() string {
+	return fmt.Sprintf("%d -> %d bytes (%.1f%% saved), %d assets and %d layers removed, %d properties collapsed, %d fields stripped",
+		s.SizeBefore, s.SizeAfter, s.Saved()*100,
+		s.AssetsRemoved, s.LayersRemoved, s.KeyframesCollapsed, s.FieldsStripped)
+}
+
+// editorFields are removed from every object by StripEditorFields.
+var editorFields = []string{"mn", "ix", "cix", "np", "nm"}
+
+// rootEditorFields are removed from the root composition by StripEditorFields.
+var rootEditorFields = []string{"meta", "props"}
+
+// Optimize returns minified animation data.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("animation.json")
+//	optimized, stats, err := golottie.Optimize(data, golottie.DefaultOptimizeOptions)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Println(stats)
+//	os.WriteFile("animation.min.json", optimized, 0o644)
+func Optimize(data []byte, opts OptimizeOptions) ([]byte, *OptimizeStats, error) {
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("error optimizing animation: %w", ErrNilAnimationData)
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("error optimizing animation: %w", err)
+	}
+	stats := &OptimizeStats{SizeBefore: len(data)}
+	if opts.RemoveHiddenLayers {
+		stats.LayersRemoved += removeHiddenLayers(root)
+		for _, a := range asSlice(root["assets"]) {
+			if asset, ok := a.(map[string]interface{}); ok {
+				stats.LayersRemoved += removeHiddenLayers(asset)
+			}
+		}
+	}
+	if opts.RemoveUnusedAssets {
+		stats.AssetsRemoved = removeUnusedAssets(root)
+	}
+	if opts.CollapseStaticKeyframes {
+		walkJSON(root, func(m map[string]interface{}) {
+			if collapseStatic(m) {
+				stats.KeyframesCollapsed++
+			}
+		})
+	}
+	if opts.StripEditorFields {
+		stats.FieldsStripped = stripEditorFields(root)
+	}
+	v := interface{}(root)
+	if opts.Precision >= 0 {
+		v = roundNumbers(v, math.Pow10(opts.Precision))
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, nil, err
+	}
+	out := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	stats.SizeAfter = len(out)
+	return out, stats, nil
+}
+
+// Optimize returns minified initial animation data, see [Optimize].
+func (a *AnimationData) Optimize(opts OptimizeOptions) ([]byte, *OptimizeStats, error) {
+	if a.err != nil {
+		return nil, nil, a.err
+	}
+	return Optimize(a.data, opts)
+}
+
+func asSlice(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
+
+// removeHiddenLayers removes hidden layers of the composition keeping
+// the ones used as parents or matte sources.
+func removeHiddenLayers(comp map[string]interface{}) int {
+	layers := asSlice(comp["layers"])
+	parents := make(map[float64]bool)
+	for _, l := range layers {
+		layer, _ := l.(map[string]interface{})
+		if parent, ok := layer["parent"].(float64); ok {
+			parents[parent] = true
+		}
+	}
+	var kept []interface{}
+	for _, l := range layers {
+		layer, _ := l.(map[string]interface{})
+		hidden, _ := layer["hd"].(bool)
+		ind, _ := layer["ind"].(float64)
+		td, _ := layer["td"].(float64)
+		if hidden && !parents[ind] && td == 0 {
+			continue
+		}
+		kept = append(kept, l)
+	}
+	if kept == nil {
+		kept = []interface{}{}
+	}
+	if _, ok := comp["layers"]; ok {
+		comp["layers"] = kept
+	}
+	return len(layers) - len(kept)
+}
+
+// removeUnusedAssets removes assets not referenced from the root composition.
+func removeUnusedAssets(root map[string]interface{}) int {
+	assets := asSlice(root["assets"])
+	if assets == nil {
+		return 0
+	}
+	byID := make(map[string]gson.JSON)
+	for _, a := range assets {
+		asset := gson.New(a)
+		byID[str(asset.Get("id"))] = asset
+	}
+	used := make(map[string]bool)
+	for _, id := range usedAssets(gson.New(root), byID) {
+		used[id] = true
+	}
+	var kept []interface{}
+	for _, a := range assets {
+		if used[str(gson.New(a).Get("id"))] {
+			kept = append(kept, a)
+		}
+	}
+	if kept == nil {
+		kept = []interface{}{}
+	}
+	root["assets"] = kept
+	return len(assets) - len(kept)
+}
+
+// collapseStatic replaces animated property with a static value if every
+// keyframe has the same value. Properties driven by expressions are kept
+// since expressions may depend on keyframes, and so are positions with
+// spatial tangents which move along a curved path between equal values.
+func collapseStatic(prop map[string]interface{}) bool {
+	if a, _ := prop["a"].(float64); a != 1 {
+		return false
+	}
+	if _, ok := prop["x"]; ok {
+		return false
+	}
+	keyframes, ok := prop["k"].([]interface{})
+	if !ok || len(keyframes) == 0 {
+		return false
+	}
+	var value []interface{}
+	for _, kf := range keyframes {
+		keyframe, ok := kf.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if e, ok := keyframe["e"]; ok && !reflect.DeepEqual(e, keyframe["s"]) {
+			return false
+		}
+		if hasTangent(keyframe["ti"]) || hasTangent(keyframe["to"]) {
+			return false
+		}
+		s, ok := keyframe["s"].([]interface{})
+		if !ok {
+			// Legacy last keyframes contain only time
+			continue
+		}
+		if value == nil {
+			value = s
+		} else if !reflect.DeepEqual(value, s) {
+			return false
+		}
+	}
+	if value == nil {
+		return false
+	}
+	prop["a"] = 0.0
+	if len(value) == 1 {
+		// Scalar and shape values are wrapped into arrays inside keyframes
+		prop["k"] = value[0]
+	} else {
+		prop["k"] = value
+	}
+	return true
+}
+
+// hasTangent tells whether spatial tangent v has a non-zero component.
+func hasTangent(v interface{}) bool {
+	tangent, _ := v.([]interface{})
+	for _, c := range tangent {
+		if n, _ := c.(float64); n != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// stripEditorFields removes editor only fields returning the number
+// of removed fields. Names are kept if animation uses expressions.
+func stripEditorFields(root map[string]interface{}) int {
+	fields := editorFields
+	if countExpressionsIn(root) > 0 {
+		fields = nil
+	}
+	stripped := 0
+	for _, key := range rootEditorFields {
+		if _, ok := root[key]; ok {
+			delete(root, key)
+			stripped++
+		}
+	}
+	name, hasName := root["nm"]
+	walkJSON(root, func(m map[string]interface{}) {
+		for _, key := range fields {
+			if _, ok := m[key]; ok {
+				delete(m, key)
+				stripped++
+			}
+		}
+	})
+	if _, kept := root["nm"]; hasName && !kept {
+		// Animation name is used as metadata
+		root["nm"] = name
+		stripped--
+	}
+	return stripped
+}
+
+// roundNumbers rounds every number in the decoded JSON value.
+func roundNumbers(v interface{}, scale float64) interface{} {
+	switch v := v.(type) {
+	case float64:
+		return math.Round(v*scale) / scale
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = roundNumbers(child, scale)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = roundNumbers(child, scale)
+		}
+	}
+	return v
+}
+
+// FrameDiff is the difference between two rendered frames.
+type FrameDiff struct {
+	Frame int `json:"frame"`
+	// Changed is the fraction of pixels which differ by more than the threshold.
+	Changed float64 `json:"changed"`
+}
+
+// CompareFrames decodes two frames and returns the fraction of pixels
+// which have at least one channel differing by more than threshold.
+// Frames of different sizes are considered completely different.
+func CompareFrames(a, b []byte, threshold uint8) (float64, error) {
+	imgA, _, err := image.Decode(bytes.NewReader(a))
+	if err != nil {
+		return 0, err
+	}
+	imgB, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+	boundsA, boundsB := imgA.Bounds(), imgB.Bounds()
+	if boundsA.Dx() != boundsB.Dx() || boundsA.Dy() != boundsB.Dy() {
+		return 1, nil
+	}
+	if boundsA.Empty() {
+		return 0, nil
+	}
+	limit := uint32(threshold) * 0x101
+	changed := 0
+	for y := 0; y < boundsA.Dy(); y++ {
+		for x := 0; x < boundsA.Dx(); x++ {
+			r1, g1, b1, a1 := imgA.At(boundsA.Min.X+x, boundsA.Min.Y+y).RGBA()
+			r2, g2, b2, a2 := imgB.At(boundsB.Min.X+x, boundsB.Min.Y+y).RGBA()
+			if delta(r1, r2) > limit || delta(g1, g2) > limit || delta(b1, b2) > limit || delta(a1, a2) > limit {
+				changed++
+			}
+		}
+	}
+	return float64(changed) / float64(boundsA.Dx()*boundsA.Dy()), nil
+}
+
+func delta(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// CompareRender renders every step-th frame of both animations and returns the
+// frames which differ by more than tolerance, see [CompareFrames].
+//
+// Example:
+//
+//	before, _ := golottie.NewAnimation(data).WithDefaultTemplate()
+//	after, _ := golottie.NewAnimation(optimized).WithDefaultTemplate()
+//	diffs, err := renderer.CompareRender(before, after, 1, 2, 0.001)
+func (r *Renderer) CompareRender(before, after Animation, step int, threshold uint8, tolerance float64) ([]FrameDiff, error) {
+	if step < 1 {
+		step = 1
+	}
+	render := func(animation Animation) ([][]byte, error) {
+		if err := r.SetAnimation(animation); err != nil {
+			return nil, err
+		}
+		var frames [][]byte
+		for frame := 0; frame < r.framesTotal; frame += step {
+			var buf []byte
+			if err := r.GoToFrame(frame); err != nil {
+				return nil, err
+			}
+			if err := r.RenderFrame(&buf); err != nil {
+				return nil, err
+			}
+			frames = append(frames, buf)
+		}
+		return frames, nil
+	}
+	framesBefore, err := render(before)
+	if err != nil {
+		return nil, err
+	}
+	framesAfter, err := render(after)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []FrameDiff
+	for i := 0; i < len(framesBefore) || i < len(framesAfter); i++ {
+		changed := 1.0
+		if i < len(framesBefore) && i < len(framesAfter) {
+			if changed, err = CompareFrames(framesBefore[i], framesAfter[i], threshold); err != nil {
+				return diffs, err
+			}
+		}
+		if changed > tolerance {
+			diffs = append(diffs, FrameDiff{Frame: i * step, Changed: changed})
+		}
+	}
+	return diffs, nil
+}
@@ -0,0 +1,116 @@
+package golottie
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmood/gson"
+)
+
+var bloatedAnimData = []byte(`{"v":"5.7.4","nm":"bloated","fr":29.9700012207031,"ip":0,"op":60.0000024438501,"w":100,"h":100,
+"meta":{"g":"LottieFiles AE"},
+"assets":[{"id":"used","layers":[]},{"id":"unused","layers":[]},{"id":"img","p":"img.png","u":"images/"}],
+"layers":[
+{"ind":1,"ty":0,"nm":"comp","mn":"ADBE","refId":"used","ip":0,"op":60,"st":0,"ks":{
+"o":{"a":1,"k":[{"t":0,"s":[100]},{"t":30,"s":[100]},{"t":60}],"ix":11},
+"p":{"a":1,"k":[{"t":0,"s":[50.123456,50,0]},{"t":30,"s":[60,50,0]}],"ix":2}}},
+{"ind":2,"ty":3,"nm":"hidden","hd":true,"ip":0,"op":60,"st":0,"ks":{}},
+{"ind":3,"ty":3,"nm":"hidden parent","hd":true,"ip":0,"op":60,"st":0,"ks":{}},
+{"ind":4,"ty":3,"nm":"child","parent":3,"ip":0,"op":60,"st":0,"ks":{}}
+]}`)
+
+func Test_Optimize(t *testing.T) {
+	data, stats, err := Optimize(bloatedAnimData, DefaultOptimizeOptions)
+	assert.NoError(t, err)
+	assert.Less(t, stats.SizeAfter, stats.SizeBefore)
+	assert.Greater(t, stats.Saved(), 0.0)
+	assert.Equal(t, 1, stats.LayersRemoved)
+	assert.Equal(t, 2, stats.AssetsRemoved)
+	assert.Equal(t, 1, stats.KeyframesCollapsed)
+	assert.NotEmpty(t, stats.String())
+
+	optimized := gson.New(data)
+	assert.Equal(t, 29.97, optimized.Get("fr").Num())
+	assert.Equal(t, "bloated", optimized.Get("nm").Str())
+	assert.False(t, optimized.Has("meta"))
+	assert.Len(t, optimized.Get("layers").Arr(), 3)
+	assert.Len(t, optimized.Get("assets").Arr(), 1)
+	assert.False(t, optimized.Has("layers.0.nm"))
+	assert.False(t, optimized.Has("layers.0.ks.o.ix"))
+	assert.Equal(t, 0, optimized.Get("layers.0.ks.o.a").Int())
+	assert.Equal(t, 100.0, optimized.Get("layers.0.ks.o.k").Num())
+	assert.Equal(t, 1, optimized.Get("layers.0.ks.p.a").Int())
+	assert.Equal(t, 50.123, optimized.Get("layers.0.ks.p.k.0.s.0").Num())
+
+	report, err := Validate(data)
+	assert.NoError(t, err)
+	assert.Less(t, report.Max(), SeverityError)
+
+	t.Run("Expressions_keep_names", func(t *testing.T) {
+		data, stats, err := Optimize(exprAnimData, DefaultOptimizeOptions)
+		assert.NoError(t, err)
+		assert.Equal(t, "Null", gson.New(data).Get("layers.0.nm").Str())
+		assert.Equal(t, "expr", gson.New(data).Get("nm").Str())
+		// the kept animation name isn't counted
+		assert.Zero(t, stats.FieldsStripped)
+	})
+	t.Run("No_optimizations", func(t *testing.T) {
+		data, stats, err := Optimize(animData, OptimizeOptions{Precision: -1})
+		assert.NoError(t, err)
+		assert.Zero(t, stats.LayersRemoved+stats.AssetsRemoved+stats.KeyframesCollapsed+stats.FieldsStripped)
+		assert.Equal(t, gson.New(animData).Get("fr").Num(), gson.New(data).Get("fr").Num())
+	})
+	t.Run("Bad_animation", func(t *testing.T) {
+		_, _, err := Optimize(nil, DefaultOptimizeOptions)
+		assert.ErrorIs(t, err, ErrNilAnimationData)
+		_, _, err = NewAnimation([]byte("[]")).Optimize(DefaultOptimizeOptions)
+		assert.Error(t, err)
+	})
+}
+
+func Test_collapseStatic(t *testing.T) {
+	tests := []struct {
+		name      string
+		prop      string
+		collapsed bool
+	}{
+		{"Equal_values", `{"a":1,"k":[{"t":0,"s":[50,50]},{"t":30,"s":[50,50]}]}`, true},
+		{"Zero_tangents", `{"a":1,"k":[{"t":0,"s":[50,50],"to":[0,0],"ti":[0,0]},{"t":30,"s":[50,50]}]}`, true},
+		{"Curved_path", `{"a":1,"k":[{"t":0,"s":[50,50],"to":[10,0],"ti":[-10,0]},{"t":30,"s":[50,50]}]}`, false},
+		{"Changing_values", `{"a":1,"k":[{"t":0,"s":[50,50]},{"t":30,"s":[60,50]}]}`, false},
+		{"Expression", `{"a":1,"k":[{"t":0,"s":[50,50]},{"t":30,"s":[50,50]}],"x":"loopOut()"}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prop, _ := gson.NewFrom(tt.prop).Val().(map[string]interface{})
+			assert.Equal(t, tt.collapsed, collapseStatic(prop))
+		})
+	}
+}
+
+func Test_CompareFrames(t *testing.T) {
+	encode := func(img image.Image) []byte {
+		var buf bytes.Buffer
+		assert.NoError(t, png.Encode(&buf, img))
+		return buf.Bytes()
+	}
+	a := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	b := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	b.Set(0, 0, color.NRGBA{R: 10, A: 255})
+	b.Set(1, 1, color.NRGBA{R: 1})
+
+	changed, err := CompareFrames(encode(a), encode(b), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.25, changed)
+
+	changed, err = CompareFrames(encode(a), encode(image.NewNRGBA(image.Rect(0, 0, 1, 1))), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, changed)
+
+	_, err = CompareFrames([]byte("ʕ•ᴥ•ʔ"), encode(a), 0)
+	assert.Error(t, err)
+}
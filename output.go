@@ -0,0 +1,287 @@
+package golottie
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	outputVerbRe = regexp.MustCompile(`%(0?\d*)([a-zA-Z%])`)
+	outputSpecRe = regexp.MustCompile(`^(0?)(\d*)(?:\.(\d+))?$`)
+)
+
+// outputVars lists the variables of output templates, the value tells
+// if the variable is a number.
+var outputVars = map[string]bool{
+	"name":   false,
+	"frame":  true,
+	"source": true,
+	"time":   true,
+	"marker": false,
+	"layer":  false,
+	"width":  true,
+	"height": true,
+	"scale":  true,
+}
+
+// OutputVars are the values of output template variables.
+type OutputVars struct {
+	// Name is the animation name.
+	Name string
+	// Frame is the output frame number.
+	Frame int
+	// Source is the animation frame number, counted from the in point.
+	Source int
+	// Time is the frame time in milliseconds from the animation start.
+	Time int
+	// Marker is the name of the marker the frame is in, if any.
+	Marker string
+	// Layer is the name of the layer rendered on its own, if any.
+	Layer  string
+	Width  int
+	Height int
+	// Scale is the rendered size relative to the animation size.
+	Scale float64
+}
+
+// outputPart is either literal text or a variable of a template.
+type outputPart struct {
+	text  string
+	name  string
+	width int
+	zero  bool
+	prec  int
+}
+
+// OutputTemplate names output files with variables in braces, such as
+// "out/{name}/{marker}/{frame:04}@{scale}x.png". Numbers accept a width
+// spec padded with spaces or zeros if it starts with 0, scale also takes
+// a precision such as {scale:.2}. Braces are escaped by doubling them.
+//
+// Variables are name, frame, source, time, marker, layer, width, height
+// and scale, see [OutputVars].
+type OutputTemplate struct {
+	raw   string
+	parts []outputPart
+}
+
+// ParseOutputTemplate parses and validates the output template. Legacy
+// sprintf patterns such as "render/%04d.png" are accepted and name the
+// files by the output frame number, verbs other than %d are rejected.
+func ParseOutputTemplate(s string) (*OutputTemplate, error) {
+	tmpl, err := parseOutputLegacy(s)
+	if err != nil {
+		return nil, err
+	}
+	t := &OutputTemplate{raw: s}
+	var text strings.Builder
+	for i := 0; i < len(tmpl); i++ {
+		switch c := tmpl[i]; {
+		case c == '{' && strings.HasPrefix(tmpl[i:], "{{"), c == '}' && strings.HasPrefix(tmpl[i:], "}}"):
+			text.WriteByte(c)
+			i++
+		case c == '}':
+			return nil, fmt.Errorf("error parsing output template %q: unexpected }", s)
+		case c == '{':
+			end := strings.IndexByte(tmpl[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("error parsing output template %q: unclosed {", s)
+			}
+			part, err := parseOutputVar(tmpl[i+1 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("error parsing output template %q: %w", s, err)
+			}
+			if text.Len() > 0 {
+				t.parts = append(t.parts, outputPart{text: text.String()})
+				text.Reset()
+			}
+			t.parts = append(t.parts, part)
+			i += end
+		default:
+			text.WriteByte(c)
+		}
+	}
+	if text.Len() > 0 {
+		t.parts = append(t.parts, outputPart{text: text.String()})
+	}
+	return t, nil
+}
+
+// parseOutputLegacy converts sprintf verbs to template variables.
+func parseOutputLegacy(s string) (string, error) {
+	var err error
+	tmpl := outputVerbRe.ReplaceAllStringFunc(s, func(verb string) string {
+		m := outputVerbRe.FindStringSubmatch(verb)
+		switch {
+		case m[2] == "%" && m[1] == "":
+			return "%"
+		case m[2] == "d":
+			if m[1] == "" {
+				return "{frame}"
+			}
+			return "{frame:" + m[1] + "}"
+		}
+		if err == nil {
+			err = fmt.Errorf("error parsing output template %q: unsupported verb %s, only %%d is supported", s, verb)
+		}
+		return verb
+	})
+	return tmpl, err
+}
+
+func parseOutputVar(s string) (outputPart, error) {
+	name, spec, hasSpec := strings.Cut(s, ":")
+	number, ok := outputVars[name]
+	if !ok {
+		return outputPart{}, fmt.Errorf("unknown variable {%s}", name)
+	}
+	part := outputPart{name: name, prec: -1}
+	if !hasSpec {
+		return part, nil
+	}
+	m := outputSpecRe.FindStringSubmatch(spec)
+	if !number || m == nil || spec == "" || (m[3] != "" && name != "scale") {
+		return outputPart{}, fmt.Errorf("invalid format %q of {%s}", spec, name)
+	}
+	part.zero = m[1] != ""
+	if m[2] != "" {
+		part.width, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		part.prec, _ = strconv.Atoi(m[3])
+	}
+	return part, nil
+}
+
+// String returns the template as it was parsed.
+func (t *OutputTemplate) String() string {
+	return t.raw
+}
+
+// Has reports whether the template uses any of the variables.
+func (t *OutputTemplate) Has(names ...string) bool {
+	for _, part := range t.parts {
+		for _, name := range names {
+			if part.name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PerFrame reports whether the template names every frame differently.
+func (t *OutputTemplate) PerFrame() bool {
+	return t.Has("frame", "source", "time")
+}
+
+// Execute returns the name for the variables. Path separators in the
+// string values are replaced with underscores, and so are path elements
+// made of string values which are empty, "." or "..", so names taken
+// from the animation stay inside the output directory.
+func (t *OutputTemplate) Execute(vars OutputVars) string {
+	var b strings.Builder
+	// elements holds indexes of the path elements with string values
+	elements := make(map[int]bool)
+	for _, part := range t.parts {
+		var value string
+		switch part.name {
+		case "":
+			b.WriteString(part.text)
+			continue
+		case "name":
+			value = outputString(vars.Name)
+		case "marker":
+			value = outputString(vars.Marker)
+		case "layer":
+			value = outputString(vars.Layer)
+		case "frame":
+			value = strconv.Itoa(vars.Frame)
+		case "source":
+			value = strconv.Itoa(vars.Source)
+		case "time":
+			value = strconv.Itoa(vars.Time)
+		case "width":
+			value = strconv.Itoa(vars.Width)
+		case "height":
+			value = strconv.Itoa(vars.Height)
+		case "scale":
+			value = strconv.FormatFloat(vars.Scale, 'f', part.prec, 64)
+		}
+		if part.name == "name" || part.name == "marker" || part.name == "layer" {
+			elements[strings.Count(b.String(), "/")] = true
+		}
+		if pad := part.width - len(value); pad > 0 {
+			if part.zero {
+				// keep the sign in front of zeros
+				sign := strings.HasPrefix(value, "-")
+				value = strings.Repeat("0", pad) + strings.TrimPrefix(value, "-")
+				if sign {
+					value = "-" + value
+				}
+			} else {
+				value = strings.Repeat(" ", pad) + value
+			}
+		}
+		b.WriteString(value)
+	}
+	if len(elements) == 0 {
+		return b.String()
+	}
+	path := strings.Split(b.String(), "/")
+	for i := range elements {
+		if e := path[i]; e == "" || e == "." || e == ".." {
+			path[i] = "_"
+		}
+	}
+	return strings.Join(path, "/")
+}
+
+func outputString(s string) string {
+	return strings.NewReplacer("/", "_", `\`, "_").Replace(s)
+}
+
+// FrameNamer returns the output name of a frame.
+type FrameNamer func(f Frame) string
+
+// NewFrameNamer returns namer executing t for frames of the animation
+// described by info. Name, Width, Height, Scale and Layer of vars are
+// used as is, Frame is added to zero based frame numbers and the rest is
+// set per frame. Name and Scale default to the animation name and the
+// rendered width relative to the animation width.
+//
+// Example:
+//
+//	tmpl, err := golottie.ParseOutputTemplate("out/{name}/{marker}/{frame:04}.png")
+//	...
+//	info, err := animation.Info()
+//	...
+//	namer := golottie.NewFrameNamer(tmpl, info, golottie.OutputVars{Width: 512, Height: 512})
+//	err = renderer.RenderAll(golottie.NewDirSinkFunc(namer))
+func NewFrameNamer(t *OutputTemplate, info *Info, vars OutputVars) FrameNamer {
+	if vars.Name == "" {
+		vars.Name = info.Name
+	}
+	if vars.Scale == 0 && info.Width > 0 {
+		vars.Scale = float64(vars.Width) / info.Width
+	}
+	start := vars.Frame
+	return func(f Frame) string {
+		v := vars
+		v.Frame = start + f.Num
+		v.Source = int(info.InPoint) + f.Num
+		if info.FrameRate > 0 {
+			v.Time = int(math.Round(float64(f.Num) * 1000 / info.FrameRate))
+		}
+		for _, marker := range info.Markers {
+			if float64(v.Source) >= marker.Time && float64(v.Source) < marker.Time+math.Max(marker.Duration, 1) {
+				v.Marker = marker.Comment
+				break
+			}
+		}
+		return t.Execute(v)
+	}
+}
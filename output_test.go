@@ -0,0 +1,81 @@
+package golottie
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseOutputTemplate(t *testing.T) {
+	vars := OutputVars{
+		Name:   "hero/idle",
+		Frame:  7,
+		Source: 12,
+		Time:   500,
+		Marker: "intro",
+		Width:  1024,
+		Height: 512,
+		Scale:  2,
+	}
+	for tmpl, want := range map[string]string{
+		"out/{name}/{marker}/{frame:04}@{scale}x.png": "out/hero_idle/intro/0007@2x.png",
+		"{source:3}-{time}-{width}x{height}.png":      " 12-500-1024x512.png",
+		"{scale:.2}/{layer}{frame}":                   "2.00/7",
+		"{{{frame}}}.png":                             "{7}.png",
+		"render/%04d.png":                             "render/0007.png",
+		"render/%d%%.png":                             "render/7%.png",
+	} {
+		parsed, err := ParseOutputTemplate(tmpl)
+		require.NoError(t, err, tmpl)
+		assert.Equal(t, want, parsed.Execute(vars), tmpl)
+		assert.Equal(t, tmpl, parsed.String())
+	}
+	// names from the animation don't leave the output directory
+	parsed, err := ParseOutputTemplate("../out/{marker}/{layer}/{name}{frame}.png")
+	require.NoError(t, err)
+	assert.Equal(t, "../out/_/_/..7.png", parsed.Execute(OutputVars{Name: "..", Marker: "..", Layer: ".", Frame: 7}))
+	assert.Equal(t, "../out/_/_/7.png", parsed.Execute(OutputVars{Frame: 7}))
+	for _, tmpl := range []string{
+		"render/%s.png",
+		"render/%04x.png",
+		"{frames}.png",
+		"{frame",
+		"frame}",
+		"{name:04}",
+		"{frame:.2}",
+		"{frame:x}",
+	} {
+		_, err := ParseOutputTemplate(tmpl)
+		assert.Error(t, err, tmpl)
+	}
+
+	parsed, err = ParseOutputTemplate("{name}.png")
+	require.NoError(t, err)
+	assert.False(t, parsed.PerFrame())
+	parsed, err = ParseOutputTemplate("{time}.png")
+	require.NoError(t, err)
+	assert.True(t, parsed.PerFrame())
+}
+
+func Test_NewFrameNamer(t *testing.T) {
+	info, err := NewAnimation(exprAnimData).Info()
+	require.NoError(t, err)
+	tmpl, err := ParseOutputTemplate("{name}/{marker}/{frame:03}-{source}-{time}@{scale}x.png")
+	require.NoError(t, err)
+	name := NewFrameNamer(tmpl, info, OutputVars{Frame: 1, Width: 1024, Height: 512})
+	assert.Equal(t, "expr/intro/001-0-0@2x.png", name(Frame{Num: 0}))
+	assert.Equal(t, "expr/_/031-30-500@2x.png", name(Frame{Num: 30}))
+
+	dir := t.TempDir()
+	tmpl, err = ParseOutputTemplate(filepath.Join(dir, "{marker}", "{frame:02}.png"))
+	require.NoError(t, err)
+	writeFrames(t, NewDirSinkFunc(NewFrameNamer(tmpl, info, OutputVars{})), 0, 40)
+	for name, want := range map[string]byte{"intro/00.png": 0, "_/40.png": 40} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{want}, data)
+	}
+}
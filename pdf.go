@@ -0,0 +1,187 @@
+package golottie
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"strings"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// pdfPointsPerPixel maps CSS pixels to PDF points.
+const pdfPointsPerPixel = 0.75
+
+// WriteImagePDF writes PDF with every image on its own page sized to
+// the image. Transparent pixels are drawn over white.
+func WriteImagePDF(w io.Writer, images ...image.Image) error {
+	if len(images) == 0 {
+		return ErrNoFrames
+	}
+	pdf := &pdfWriter{}
+	pdf.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// catalog and page tree are objects 1 and 2, every page takes 3 more
+	kids := make([]string, len(images))
+	for i := range images {
+		kids[i] = fmt.Sprintf("%d 0 R", 3+3*i)
+	}
+	pdf.object("<< /Type /Catalog /Pages 2 0 R >>", nil)
+	pdf.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(images)), nil)
+	for i, img := range images {
+		b := img.Bounds()
+		width, height := float64(b.Dx())*pdfPointsPerPixel, float64(b.Dy())*pdfPointsPerPixel
+		pageObj := 3 + 3*i
+		pdf.object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /XObject << /Im0 %d 0 R >> >> /Contents %d 0 R >>",
+			width, height, pageObj+2, pageObj+1), nil)
+		content := []byte(fmt.Sprintf("q %.2f 0 0 %.2f 0 0 cm /Im0 Do Q", width, height))
+		pdf.object(fmt.Sprintf("<< /Length %d >>", len(content)), content)
+
+		data, err := pdfImageData(img)
+		if err != nil {
+			return err
+		}
+		pdf.object(fmt.Sprintf(
+			"<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>",
+			b.Dx(), b.Dy(), len(data)), data)
+	}
+	pdf.finish()
+	_, err := pdf.buf.WriteTo(w)
+	return err
+}
+
+// pdfImageData returns zlib compressed RGB pixels of img drawn over white.
+func pdfImageData(img image.Image) ([]byte, error) {
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Over)
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	rgb := make([]byte, 0, b.Dx()*3)
+	for y := 0; y < b.Dy(); y++ {
+		rgb = rgb[:0]
+		row := rgba.Pix[y*rgba.Stride : y*rgba.Stride+b.Dx()*4]
+		for x := 0; x < len(row); x += 4 {
+			rgb = append(rgb, row[x], row[x+1], row[x+2])
+		}
+		if _, err := zw.Write(rgb); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pdfWriter writes numbered objects tracking their offsets for xref table.
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (p *pdfWriter) object(dict string, stream []byte) {
+	p.offsets = append(p.offsets, p.buf.Len())
+	fmt.Fprintf(&p.buf, "%d 0 obj\n%s\n", len(p.offsets), dict)
+	if stream != nil {
+		p.buf.WriteString("stream\n")
+		p.buf.Write(stream)
+		p.buf.WriteString("\nendstream\n")
+	}
+	p.buf.WriteString("endobj\n")
+}
+
+func (p *pdfWriter) finish() {
+	xref := p.buf.Len()
+	fmt.Fprintf(&p.buf, "xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, offset := range p.offsets {
+		fmt.Fprintf(&p.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&p.buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, xref)
+}
+
+// cssPixelsPerInch is used to convert animation size into paper size.
+const cssPixelsPerInch = 96
+
+// RenderFramePDF prints current frame into a single page vector PDF
+// sized to the animation and writes the resulting bytes to the provided
+// frame buffer. The animation has to be rendered with SVG renderer.
+func (r *Renderer) RenderFramePDF(frameBuf *[]byte) error {
+	return chromedp.Run(r.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		*frameBuf, _, err = page.PrintToPDF().
+			WithPaperWidth(float64(r.width) / cssPixelsPerInch).
+			WithPaperHeight(float64(r.height) / cssPixelsPerInch).
+			WithMarginTop(0).
+			WithMarginBottom(0).
+			WithMarginLeft(0).
+			WithMarginRight(0).
+			WithPrintBackground(true).
+			WithPageRanges("1").
+			Do(ctx)
+		return err
+	}))
+}
+
+// RenderPDF renders the provided frames into a multi-page vector PDF,
+// one frame per page sized to the animation. Frame SVGs are printed
+// from a separate browser tab, so the player page is left intact and
+// the renderer continues from the frame after the last one.
+//
+// Example:
+//
+//	frames := make([]int, 0)
+//	for i := 10; i < 20; i++ {
+//		frames = append(frames, i)
+//	}
+//	data, err := renderer.RenderPDF(frames)
+func (r *Renderer) RenderPDF(frames []int) ([]byte, error) {
+	if len(frames) == 0 {
+		return nil, ErrNoFrames
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<!DOCTYPE html><html><head><meta charset="UTF-8"><style>`+
+		`@page{size:%[1]dpx %[2]dpx;margin:0}html,body{margin:0}`+
+		`.page{width:%[1]dpx;height:%[2]dpx;overflow:hidden;break-after:page}.page:last-child{break-after:auto}`+
+		`.page>svg{width:100%%;height:100%%}</style></head><body>`, r.width, r.height)
+	for i, n := range frames {
+		if err := r.GoToFrame(n); err != nil {
+			return nil, err
+		}
+		var svg string
+		if err := r.RenderFrameSVG(&svg); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, `<div class="page">%s</div>`, prefixSVGIDs(svg, fmt.Sprintf("f%d-", i)))
+	}
+	b.WriteString(`</body></html>`)
+
+	tab, cancel := chromedp.NewContext(r.ctx)
+	defer cancel()
+	var data []byte
+	err := chromedp.Run(tab,
+		chromedp.Navigate("about:blank"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			tree, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			if err = page.SetDocumentContent(tree.Frame.ID, b.String()).Do(ctx); err != nil {
+				return err
+			}
+			data, _, err = page.PrintToPDF().
+				WithPreferCSSPageSize(true).
+				WithPrintBackground(true).
+				Do(ctx)
+			return err
+		}),
+	)
+	return data, err
+}
@@ -0,0 +1,38 @@
+package golottie
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WriteImagePDF(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteImagePDF(&buf,
+		image.NewNRGBA(image.Rect(0, 0, 40, 20)),
+		image.NewNRGBA(image.Rect(0, 0, 8, 8)),
+	))
+	data := buf.Bytes()
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	assert.Contains(t, string(data), "/Count 2")
+	assert.Contains(t, string(data), "/MediaBox [0 0 30.00 15.00]")
+
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(data)
+	assert.NotNil(t, m)
+	xref, _ := strconv.Atoi(string(m[1]))
+	assert.True(t, bytes.HasPrefix(data[xref:], []byte("xref\n0 9\n")))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllSubmatch(data[xref:], -1)
+	assert.Len(t, entries, 8)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		assert.True(t, bytes.HasPrefix(data[offset:], []byte(fmt.Sprintf("%d 0 obj", i+1))))
+	}
+
+	assert.ErrorIs(t, WriteImagePDF(&buf), ErrNoFrames)
+}
@@ -0,0 +1,210 @@
+package golottie
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ysmood/gson"
+)
+
+// ExtractPrecomp returns a standalone Lottie JSON which renders the precomp
+// asset with the provided id as if it were the root composition.
+//
+// The dimensions are taken from the asset itself if present, otherwise from
+// the first precomp layer referencing it and finally from the root composition.
+// The frame range is taken from the referencing layer, taking its start time
+// and time stretch into account, or, if the precomp isn't referenced anywhere,
+// from the precomp layers. Root markers within the range of the referencing
+// layer are carried over in precomp time. Only the assets used by the precomp
+// are carried over.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("icons.json")
+//	icon, err := golottie.ExtractPrecomp(data, "icon_home")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	os.WriteFile("icon_home.json", icon, 0o644)
+func ExtractPrecomp(data []byte, id string) ([]byte, error) {
+	root := gson.New(data)
+	assets := make(map[string]gson.JSON)
+	for _, asset := range root.Get("assets").Arr() {
+		assets[str(asset.Get("id"))] = asset
+	}
+	asset, ok := assets[id]
+	if !ok || !asset.Has("layers") {
+		return nil, fmt.Errorf("error extracting precomp %q: %w", id, ErrPrecompNotFound)
+	}
+
+	width, height := root.Get("w").Num(), root.Get("h").Num()
+	ip, op := 0.0, 0.0
+	ref, referenced := findPrecompLayer(root, id)
+	if referenced {
+		if ref.Has("w") && ref.Has("h") {
+			width, height = ref.Get("w").Num(), ref.Get("h").Num()
+		}
+		ip, op = precompTime(ref, ref.Get("ip").Num()), precompTime(ref, ref.Get("op").Num())
+	} else {
+		for i, layer := range asset.Get("layers").Arr() {
+			if i == 0 || layer.Get("ip").Num() < ip {
+				ip = layer.Get("ip").Num()
+			}
+			if layer.Get("op").Num() > op {
+				op = layer.Get("op").Num()
+			}
+		}
+	}
+	if asset.Has("w") && asset.Has("h") {
+		width, height = asset.Get("w").Num(), asset.Get("h").Num()
+	}
+	if ip < 0 {
+		ip = 0
+	}
+	frameRate := root.Get("fr").Num()
+	if asset.Has("fr") {
+		frameRate = asset.Get("fr").Num()
+	}
+	name := id
+	if asset.Has("nm") {
+		name = str(asset.Get("nm"))
+	}
+
+	used := []interface{}{}
+	for _, usedID := range usedAssets(asset, assets) {
+		used = append(used, assets[usedID].Val())
+	}
+	out := map[string]interface{}{
+		"v":      root.Get("v").Val(),
+		"fr":     frameRate,
+		"ip":     ip,
+		"op":     op,
+		"w":      width,
+		"h":      height,
+		"nm":     name,
+		"ddd":    root.Get("ddd").Int(),
+		"assets": used,
+		"layers": asset.Get("layers").Val(),
+	}
+	for _, key := range []string{"fonts", "chars"} {
+		if root.Has(key) {
+			out[key] = root.Get(key).Val()
+		}
+	}
+	if referenced {
+		if markers := precompMarkers(root, ref, ip, op); len(markers) > 0 {
+			out["markers"] = markers
+		}
+	}
+	return json.Marshal(out)
+}
+
+// precompTime converts time of the composition containing the precomp
+// layer ref to the precomp time, shifted by the start time and scaled by
+// the time stretch.
+func precompTime(ref gson.JSON, t float64) float64 {
+	return (t - ref.Get("st").Num()) / precompStretch(ref)
+}
+
+// precompStretch returns the time stretch of the precomp layer ref.
+func precompStretch(ref gson.JSON) float64 {
+	if sr := ref.Get("sr").Num(); sr > 0 {
+		return sr
+	}
+	return 1
+}
+
+// precompMarkers returns the root markers overlapping the [ip, op) range of
+// the precomp with times converted to the precomp time.
+func precompMarkers(root, ref gson.JSON, ip, op float64) []interface{} {
+	var markers []interface{}
+	for _, marker := range root.Get("markers").Arr() {
+		m, ok := marker.Val().(map[string]interface{})
+		if !ok {
+			continue
+		}
+		tm := precompTime(ref, marker.Get("tm").Num())
+		dr := marker.Get("dr").Num() / precompStretch(ref)
+		if tm >= op || tm+dr < ip {
+			continue
+		}
+		shifted := make(map[string]interface{}, len(m))
+		for key, value := range m {
+			shifted[key] = value
+		}
+		shifted["tm"], shifted["dr"] = tm, dr
+		markers = append(markers, shifted)
+	}
+	return markers
+}
+
+// WithPrecomp replaces animation data with the standalone animation created
+// from the precomp asset with the provided id, see [ExtractPrecomp].
+// Should be called before the template is initialized.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("icons.json")
+//	animation, err := golottie.NewAnimation(data).WithPrecomp("icon_home")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	animation, err = animation.WithDefaultTemplate()
+func (a *AnimationData) WithPrecomp(id string) (*AnimationData, error) {
+	if a.err != nil {
+		return a, a.err
+	}
+	data, err := ExtractPrecomp(a.data, id)
+	if err != nil {
+		return a, err
+	}
+	return a, a.setData(data)
+}
+
+// findPrecompLayer looks for the first precomp layer referencing the asset
+// with the provided id in the root composition and then in other assets.
+func findPrecompLayer(root gson.JSON, id string) (gson.JSON, bool) {
+	layers := root.Get("layers").Arr()
+	for _, asset := range root.Get("assets").Arr() {
+		layers = append(layers, asset.Get("layers").Arr()...)
+	}
+	for _, layer := range layers {
+		if layer.Get("ty").Int() == 0 && str(layer.Get("refId")) == id {
+			return layer, true
+		}
+	}
+	return gson.JSON{}, false
+}
+
+// usedAssets returns ids of all assets referenced by the asset layers,
+// including the assets referenced by nested precomps, in discovery order.
+func usedAssets(asset gson.JSON, assets map[string]gson.JSON) []string {
+	var used []string
+	seen := map[string]bool{str(asset.Get("id")): true}
+	queue := []gson.JSON{asset}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, layer := range current.Get("layers").Arr() {
+			refID := str(layer.Get("refId"))
+			if refID == "" || seen[refID] {
+				continue
+			}
+			seen[refID] = true
+			if ref, ok := assets[refID]; ok {
+				used = append(used, refID)
+				queue = append(queue, ref)
+			}
+		}
+	}
+	return used
+}
+
+// str returns JSON value as string or an empty string
+// if the value is missing or isn't a string.
+func str(j gson.JSON) string {
+	if s, ok := j.Val().(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package golottie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmood/gson"
+)
+
+func Test_ExtractPrecomp(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		id     string
+		err    error
+		width  int
+		height int
+		op     int
+		layers int
+	}{
+		{
+			name:   "OK_precomp",
+			data:   animData,
+			id:     "wtf",
+			width:  600,
+			height: 600,
+			op:     96,
+			layers: 2,
+		},
+		{
+			name:   "Unreferenced_precomp",
+			data:   []byte(`{"v":"5.10.0","fr":60,"w":100,"h":50,"layers":[],"assets":[{"id":"icon","layers":[{"ty":4,"ip":10,"op":40},{"ty":0,"refId":"nested","ip":5,"op":30}]},{"id":"nested","layers":[]},{"id":"unused","layers":[]}]}`),
+			id:     "icon",
+			width:  100,
+			height: 50,
+			op:     40,
+			layers: 2,
+		},
+		{
+			name: "Missing_precomp",
+			data: animData,
+			id:   "(・_・;)",
+			err:  ErrPrecompNotFound,
+		},
+		{
+			name: "Nil_data",
+			data: nil,
+			id:   "wtf",
+			err:  ErrPrecompNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ExtractPrecomp(tt.data, tt.id)
+			assert.ErrorIs(t, err, tt.err)
+			if err != nil {
+				return
+			}
+			precomp := gson.New(data)
+			assert.Equal(t, tt.width, precomp.Get("w").Int())
+			assert.Equal(t, tt.height, precomp.Get("h").Int())
+			assert.Equal(t, tt.op, precomp.Get("op").Int())
+			assert.Len(t, precomp.Get("layers").Arr(), tt.layers)
+		})
+	}
+	t.Run("Stretched_layer", func(t *testing.T) {
+		data, err := ExtractPrecomp([]byte(`{"v":"5.10.0","fr":30,"w":100,"h":100,
+"markers":[{"tm":0,"cm":"before","dr":5},{"tm":20,"cm":"intro","dr":10},{"tm":100,"cm":"after","dr":0}],
+"layers":[{"ty":0,"refId":"icon","ip":10,"op":70,"st":10,"sr":2}],
+"assets":[{"id":"icon","layers":[]}]}`), "icon")
+		assert.NoError(t, err)
+		precomp := gson.New(data)
+		assert.Equal(t, 0, precomp.Get("ip").Int())
+		assert.Equal(t, 30, precomp.Get("op").Int())
+		markers := precomp.Get("markers").Arr()
+		if assert.Len(t, markers, 1) {
+			assert.Equal(t, "intro", markers[0].Get("cm").Str())
+			assert.Equal(t, 5.0, markers[0].Get("tm").Num())
+			assert.Equal(t, 5.0, markers[0].Get("dr").Num())
+		}
+	})
+	t.Run("Used_assets", func(t *testing.T) {
+		data, err := ExtractPrecomp(tests[1].data, "icon")
+		assert.NoError(t, err)
+		assets := gson.New(data).Get("assets").Arr()
+		assert.Len(t, assets, 1)
+		assert.Equal(t, "nested", assets[0].Get("id").Str())
+		assert.Equal(t, 5, gson.New(data).Get("ip").Int())
+	})
+}
+
+func Test_WithPrecomp(t *testing.T) {
+	animation, err := NewAnimation(animData).WithPrecomp("wtf")
+	assert.NoError(t, err)
+	assert.Equal(t, 96, animation.GetFramesTotal())
+	assert.Equal(t, 600, animation.GetWidth())
+	assert.Equal(t, 600, animation.GetHeight())
+	_, err = animation.WithDefaultTemplate()
+	assert.NoError(t, err)
+
+	_, err = NewAnimation(animData).WithPrecomp("(・_・;)")
+	assert.ErrorIs(t, err, ErrPrecompNotFound)
+}
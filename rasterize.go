@@ -0,0 +1,90 @@
+package golottie
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+var svgPrologRe = regexp.MustCompile(`^\s*<\?xml[^>]*\?>`)
+
+// svgLoadedJS resolves once the fonts and the images of the document
+// are decoded and painted, images failing to load are skipped.
+const svgLoadedJS = `Promise.all([
+	document.fonts.ready,
+	...Array.from(document.querySelectorAll("image"), (el) => {
+		const img = new Image();
+		img.src = el.href.baseVal;
+		return img.decode().catch(() => {});
+	}),
+]).then(() => new Promise((resolve) => requestAnimationFrame(() => requestAnimationFrame(resolve))))`
+
+// SVGRasterizer renders SVG documents to PNG in a browser tab of its
+// own, so SVG frames extracted with [Renderer.RenderFrameSVG] can be
+// rasterized at any size without external tools. A rasterizer is not
+// safe for concurrent use, create one per worker instead.
+//
+// Example:
+//
+//	rasterizer, err := golottie.NewSVGRasterizer(ctx, 512, 512)
+//	...
+//	defer rasterizer.Close()
+//	var png []byte
+//	err = rasterizer.Rasterize(svg, &png)
+type SVGRasterizer struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewSVGRasterizer opens a browser tab with transparent background and
+// width x height viewport from the parent browser context ctx.
+func NewSVGRasterizer(ctx context.Context, width, height int) (*SVGRasterizer, error) {
+	tab, cancel := chromedp.NewContext(ctx)
+	if err := chromedp.Run(tab,
+		emulation.SetDefaultBackgroundColorOverride().WithColor(&cdp.RGBA{R: 0, G: 0, B: 0, A: 0}),
+		chromedp.EmulateViewport(int64(width), int64(height)),
+		chromedp.Navigate("about:blank"),
+	); err != nil {
+		cancel()
+		return nil, fmt.Errorf("error opening rasterizer tab: %w", err)
+	}
+	return &SVGRasterizer{ctx: tab, cancel: cancel}, nil
+}
+
+// Rasterize renders svg scaled to the viewport and writes PNG bytes to
+// the provided frame buffer once fonts and images are decoded. External
+// resources aren't resolved, use [StandaloneSVG] to embed them first.
+func (r *SVGRasterizer) Rasterize(svg string, frameBuf *[]byte) error {
+	doc := `<!DOCTYPE html><html><head><meta charset="UTF-8"><style>` +
+		`html,body{margin:0;overflow:hidden}` +
+		`body>svg{display:block;width:100vw!important;height:100vh!important}` +
+		`</style></head><body>` + svgPrologRe.ReplaceAllString(svg, "") + `</body></html>`
+	return chromedp.Run(r.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		tree, err := page.GetFrameTree().Do(ctx)
+		if err != nil {
+			return err
+		}
+		if err = page.SetDocumentContent(tree.Frame.ID, doc).Do(ctx); err != nil {
+			return err
+		}
+		if _, exp, err := runtime.Evaluate(svgLoadedJS).WithAwaitPromise(true).Do(ctx); err != nil {
+			return err
+		} else if exp != nil {
+			return fmt.Errorf("error loading svg: %w", exp)
+		}
+		*frameBuf, err = page.CaptureScreenshot().Do(ctx)
+		return err
+	}))
+}
+
+// Close closes the rasterizer tab.
+func (r *SVGRasterizer) Close() error {
+	defer r.cancel()
+	return chromedp.Cancel(r.ctx)
+}
@@ -0,0 +1,568 @@
+package golottie
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	s3DefaultRegion      = "us-east-1"
+	s3DefaultPartSize    = 8 << 20
+	s3DefaultConcurrency = 4
+	s3DefaultRetries     = 3
+	s3DefaultRetryDelay  = 200 * time.Millisecond
+	s3Algorithm          = "AWS4-HMAC-SHA256"
+	s3DateFormat         = "20060102T150405Z"
+)
+
+// S3Config configures connection to S3-compatible object storage.
+type S3Config struct {
+	// Endpoint is the base URL of the storage, such as http://localhost:9000.
+	// AWS endpoint of the region is used if it's empty.
+	Endpoint string
+	// Region is used for request signing, defaults to us-east-1.
+	Region string
+	Bucket string
+	// Prefix is prepended to object keys.
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// PathStyle addresses buckets as endpoint/bucket instead of
+	// bucket.endpoint, which most S3-compatible servers expect.
+	PathStyle bool
+	// PartSize is the size of multipart upload parts, smaller objects are
+	// uploaded with a single request. S3 requires parts of at least 5MiB.
+	PartSize int
+	// Concurrency limits uploads running at once.
+	Concurrency int
+	// Retries is the number of times failed requests are retried with
+	// exponential backoff starting at RetryDelay. Network errors, 5xx
+	// and 429 responses are retried. Zero retries 3 times, negative
+	// disables retries.
+	Retries    int
+	RetryDelay time.Duration
+	// Client sends the requests, defaults to [http.DefaultClient].
+	Client *http.Client
+}
+
+// S3ConfigFromEnv returns config with the credentials, region and
+// endpoint from the standard AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// AWS_SESSION_TOKEN, AWS_REGION and AWS_ENDPOINT_URL variables.
+// Path style addressing is used for custom endpoints.
+func S3ConfigFromEnv() S3Config {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	endpoint := os.Getenv("AWS_ENDPOINT_URL")
+	return S3Config{
+		Endpoint:        endpoint,
+		Region:          region,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		PathStyle:       endpoint != "",
+	}
+}
+
+// ParseS3URL splits s3://bucket/key URL into the bucket and the key.
+func ParseS3URL(s string) (bucket, key string, err error) {
+	if !strings.HasPrefix(s, "s3://") {
+		return "", "", fmt.Errorf("error parsing s3 url %q: expected s3://bucket/key", s)
+	}
+	bucket, key, _ = strings.Cut(strings.TrimPrefix(s, "s3://"), "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("error parsing s3 url %q: bucket is empty", s)
+	}
+	return bucket, key, nil
+}
+
+// S3Error is an error response of the storage.
+type S3Error struct {
+	StatusCode int    `xml:"-"`
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *S3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3: %s", http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("s3: %s: %s", e.Code, e.Message)
+}
+
+// s3Part is a part of multipart upload.
+type s3Part struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// s3Client sends signed requests to the storage.
+type s3Client struct {
+	cfg S3Config
+}
+
+func newS3Client(cfg S3Config) *s3Client {
+	if cfg.Region == "" {
+		cfg.Region = s3DefaultRegion
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	if cfg.PartSize <= 0 {
+		cfg.PartSize = s3DefaultPartSize
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = s3DefaultConcurrency
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = s3DefaultRetries
+	} else if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = s3DefaultRetryDelay
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	return &s3Client{cfg: cfg}
+}
+
+// objectURL returns URL of the object with the prefix applied.
+func (c *s3Client) objectURL(key string, query url.Values) (*url.URL, error) {
+	u, err := url.Parse(c.cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	path := "/" + s3Escape(c.cfg.Prefix+key, false)
+	if c.cfg.PathStyle {
+		path = "/" + s3Escape(c.cfg.Bucket, false) + path
+	} else {
+		u.Host = c.cfg.Bucket + "." + u.Host
+	}
+	return u.Parse(path + canonicalS3Query(query))
+}
+
+// do sends the request retrying failures and returns the response body.
+func (c *s3Client) do(method, key string, query url.Values, body []byte) (http.Header, []byte, error) {
+	u, err := c.objectURL(key, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		header, data, err := c.send(method, u, body)
+		retry := err != nil
+		var s3Err *S3Error
+		if e, ok := err.(*S3Error); ok {
+			s3Err = e
+			retry = e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+		}
+		if !retry || attempt >= c.cfg.Retries {
+			if err != nil && s3Err == nil {
+				err = fmt.Errorf("error sending s3 request %s %s: %w", method, u.Path, err)
+			}
+			return header, data, err
+		}
+		time.Sleep(c.cfg.RetryDelay << attempt)
+	}
+}
+
+func (c *s3Client) send(method string, u *url.URL, body []byte) (http.Header, []byte, error) {
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.ContentLength = int64(len(body))
+	signS3Request(req, HashData(body), c.cfg, time.Now())
+	resp, err := c.cfg.Client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		s3Err := &S3Error{StatusCode: resp.StatusCode}
+		//nolint:errcheck // the status code is reported anyway
+		xml.Unmarshal(data, s3Err)
+		return nil, nil, s3Err
+	}
+	return resp.Header, data, nil
+}
+
+func (c *s3Client) putObject(key string, data []byte) error {
+	_, _, err := c.do(http.MethodPut, key, nil, data)
+	return err
+}
+
+func (c *s3Client) createMultipart(key string) (string, error) {
+	_, data, err := c.do(http.MethodPost, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err = xml.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("error creating multipart upload of %q: %w", key, err)
+	}
+	return result.UploadID, nil
+}
+
+func (c *s3Client) uploadPart(key, uploadID string, n int, data []byte) (string, error) {
+	header, _, err := c.do(http.MethodPut, key, url.Values{
+		"partNumber": {strconv.Itoa(n)},
+		"uploadId":   {uploadID},
+	}, data)
+	if err != nil {
+		return "", err
+	}
+	return header.Get("ETag"), nil
+}
+
+func (c *s3Client) completeMultipart(key, uploadID string, parts []s3Part) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []s3Part `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	_, data, err := c.do(http.MethodPost, key, url.Values{"uploadId": {uploadID}}, body)
+	if err != nil {
+		return err
+	}
+	// the upload can fail after 200 OK was sent
+	if bytes.Contains(data, []byte("<Error>")) {
+		s3Err := &S3Error{StatusCode: http.StatusOK}
+		//nolint:errcheck // the error is reported anyway
+		xml.Unmarshal(data, s3Err)
+		return s3Err
+	}
+	return nil
+}
+
+func (c *s3Client) abortMultipart(key, uploadID string) {
+	//nolint:errcheck // the upload error is more relevant
+	c.do(http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil)
+}
+
+// upload uploads the object with a single request or concurrent
+// multipart upload if it's larger than the part size.
+func (c *s3Client) upload(key string, data []byte) error {
+	if len(data) <= c.cfg.PartSize {
+		return c.putObject(key, data)
+	}
+	w := newS3Writer(c, key)
+	if _, err := w.Write(data); err != nil {
+		w.abort()
+		return err
+	}
+	return w.Close()
+}
+
+// signS3Request signs req with AWS Signature Version 4. Host and every
+// header of req are signed.
+func signS3Request(req *http.Request, payloadHash string, cfg S3Config, now time.Time) {
+	amzDate := now.UTC().Format(s3DateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", cfg.SessionToken)
+	}
+	req.Header.Del("Authorization")
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := amzDate[:8]
+	scope := date + "/" + cfg.Region + "/s3/aws4_request"
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + scope + "\n" + HashData([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretAccessKey), date)
+	for _, part := range []string{cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, cfg.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign))))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3Escape percent-encodes everything but unreserved characters,
+// slashes are kept unless encodeSlash is set.
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// canonicalS3Query returns query sorted by key as signed by SigV4,
+// prefixed with "?" if it isn't empty.
+func canonicalS3Query(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return "?" + strings.Join(pairs, "&")
+}
+
+// S3Sink uploads every frame as an object named by a sprintf pattern of
+// the frame number or by a [FrameNamer], frames are uploaded concurrently
+// as they come.
+// WriteFrame is safe for concurrent use, frame data must not be modified
+// after it.
+//
+// Example:
+//
+//	cfg := golottie.S3ConfigFromEnv()
+//	cfg.Bucket, cfg.Prefix = "renders", "hero/"
+//	err := renderer.RenderAll(golottie.NewS3Sink(cfg, "%04d.png", 0))
+type S3Sink struct {
+	client *s3Client
+	name   FrameNamer
+	frames chan Frame
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	err    error
+}
+
+// NewS3Sink returns sink uploading frames to the bucket, start is added
+// to zero based frame numbers.
+func NewS3Sink(cfg S3Config, pattern string, start int) *S3Sink {
+	return NewS3SinkFunc(cfg, func(f Frame) string { return fmt.Sprintf(pattern, start+f.Num) })
+}
+
+// NewS3SinkFunc returns sink uploading frames to the keys returned by name.
+func NewS3SinkFunc(cfg S3Config, name FrameNamer) *S3Sink {
+	return &S3Sink{client: newS3Client(cfg), name: name}
+}
+
+// Open starts the upload workers.
+func (s *S3Sink) Open(Metadata) error {
+	s.err = nil
+	s.frames = make(chan Frame, s.client.cfg.Concurrency)
+	for i := 0; i < s.client.cfg.Concurrency; i++ {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for f := range s.frames {
+				if err := s.client.upload(s.name(f), f.Data); err != nil {
+					s.setErr(fmt.Errorf("error uploading frame %d: %w", f.Num, err))
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+func (s *S3Sink) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// WriteFrame queues frame for upload, the error of a failed upload is
+// returned by the following calls.
+func (s *S3Sink) WriteFrame(f Frame) error {
+	s.mu.Lock()
+	err := s.err
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	s.frames <- f
+	return nil
+}
+
+// Close waits for the uploads to finish.
+func (s *S3Sink) Close() error {
+	close(s.frames)
+	s.wg.Wait()
+	return s.err
+}
+
+// S3Writer uploads everything written to it as a single object, so it
+// can be passed to the sinks writing to [io.Writer] such as [ZipSink].
+// Data is uploaded in concurrent multipart upload parts as it's written,
+// objects smaller than a part are uploaded on Close with a single request.
+//
+// Example:
+//
+//	w := golottie.NewS3Writer(cfg, "frames.zip")
+//	err := renderer.RenderAll(golottie.NewFileSink(golottie.NewZipSink(w, golottie.DefaultArchiveOptions), w))
+type S3Writer struct {
+	client   *s3Client
+	key      string
+	buf      []byte
+	uploadID string
+	parts    []s3Part
+	sem      chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	err      error
+}
+
+// NewS3Writer returns writer uploading to the key of the bucket.
+func NewS3Writer(cfg S3Config, key string) *S3Writer {
+	return newS3Writer(newS3Client(cfg), key)
+}
+
+func newS3Writer(client *s3Client, key string) *S3Writer {
+	return &S3Writer{client: client, key: key, sem: make(chan struct{}, client.cfg.Concurrency)}
+}
+
+// Write buffers p uploading the full parts.
+func (w *S3Writer) Write(p []byte) (int, error) {
+	if err := w.error(); err != nil {
+		return 0, err
+	}
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= w.client.cfg.PartSize {
+		part := make([]byte, w.client.cfg.PartSize)
+		copy(part, w.buf)
+		w.buf = w.buf[:copy(w.buf, w.buf[len(part):])]
+		if err := w.uploadPart(part); err != nil {
+			w.mu.Lock()
+			w.err = err
+			w.mu.Unlock()
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *S3Writer) error() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// uploadPart starts the multipart upload if needed and uploads part
+// in the background.
+func (w *S3Writer) uploadPart(part []byte) error {
+	if w.uploadID == "" {
+		uploadID, err := w.client.createMultipart(w.key)
+		if err != nil {
+			return err
+		}
+		w.uploadID = uploadID
+	}
+	w.mu.Lock()
+	n := len(w.parts) + 1
+	w.parts = append(w.parts, s3Part{PartNumber: n})
+	w.mu.Unlock()
+	w.sem <- struct{}{}
+	w.wg.Add(1)
+	go func() {
+		defer func() {
+			<-w.sem
+			w.wg.Done()
+		}()
+		etag, err := w.client.uploadPart(w.key, w.uploadID, n, part)
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if err != nil && w.err == nil {
+			w.err = fmt.Errorf("error uploading part %d of %q: %w", n, w.key, err)
+		}
+		w.parts[n-1].ETag = etag
+	}()
+	return nil
+}
+
+// Close uploads the rest of the data and completes the upload. Failed
+// multipart uploads are aborted.
+func (w *S3Writer) Close() error {
+	if w.uploadID == "" {
+		if err := w.error(); err != nil {
+			return err
+		}
+		return w.client.putObject(w.key, w.buf)
+	}
+	if len(w.buf) > 0 {
+		if err := w.uploadPart(w.buf); err != nil {
+			w.abort()
+			return err
+		}
+		w.buf = nil
+	}
+	w.wg.Wait()
+	if w.err != nil {
+		w.abort()
+		return w.err
+	}
+	if err := w.client.completeMultipart(w.key, w.uploadID, w.parts); err != nil {
+		w.abort()
+		return err
+	}
+	return nil
+}
+
+func (w *S3Writer) abort() {
+	w.wg.Wait()
+	if w.uploadID != "" {
+		w.client.abortMultipart(w.key, w.uploadID)
+	}
+}
@@ -0,0 +1,318 @@
+package golottie
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// SinkFactory creates a sink writing to output, such as a file name
+// or a sprintf pattern.
+type SinkFactory func(output string) (Sink, error)
+
+var (
+	sinksMu sync.RWMutex
+	sinks   = make(map[string]SinkFactory)
+)
+
+// RegisterSink makes a sink available by name to [NewSink], registering
+// a sink with the name of an existing one replaces it. Built-in sinks
+// are "dir", "s3", "zip", "tar", "tar.gz" and "stream" along with the
+// encoders using their default options: "gif", "apng", "webp", "avi",
+// "y4m", "rgba" and "animated-svg". The "s3" sink takes s3://bucket/pattern
+// output and is configured by [S3ConfigFromEnv].
+func RegisterSink(name string, factory SinkFactory) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks[name] = factory
+}
+
+// NewSink creates the registered sink writing to output.
+//
+// Example:
+//
+//	sink, err := golottie.NewSink("zip", "frames.zip")
+//	...
+//	err = renderer.RenderAll(sink)
+func NewSink(name, output string) (Sink, error) {
+	sinksMu.RLock()
+	factory, ok := sinks[name]
+	sinksMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("error creating sink %q: %w", name, ErrUnknownSink)
+	}
+	return factory(output)
+}
+
+// Sinks returns sorted names of the registered sinks.
+func Sinks() []string {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	names := make([]string, 0, len(sinks))
+	for name := range sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterSink("dir", func(output string) (Sink, error) {
+		return NewDirSink(output, 0), nil
+	})
+	RegisterSink("s3", func(output string) (Sink, error) {
+		bucket, pattern, err := ParseS3URL(output)
+		if err != nil {
+			return nil, err
+		}
+		cfg := S3ConfigFromEnv()
+		cfg.Bucket = bucket
+		return NewS3Sink(cfg, pattern, 0), nil
+	})
+	RegisterSink("zip", fileSinkFactory(func(w io.Writer) Sink { return NewZipSink(w, DefaultArchiveOptions) }))
+	RegisterSink("tar", fileSinkFactory(func(w io.Writer) Sink { return NewTarSink(w, DefaultArchiveOptions) }))
+	RegisterSink("tar.gz", fileSinkFactory(func(w io.Writer) Sink {
+		opts := DefaultArchiveOptions
+		opts.Gzip = true
+		return NewTarSink(w, opts)
+	}))
+	RegisterSink("stream", fileSinkFactory(func(w io.Writer) Sink { return NewWriterSink(w) }))
+	RegisterSink("gif", fileSinkFactory(func(w io.Writer) Sink { return NewGIFSink(w, DefaultGIFOptions) }))
+	RegisterSink("apng", fileSinkFactory(func(w io.Writer) Sink { return NewAPNGSink(w, DefaultAPNGOptions) }))
+	RegisterSink("webp", fileSinkFactory(func(w io.Writer) Sink { return NewWebPSink(w, DefaultWebPOptions) }))
+	RegisterSink("avi", fileSinkFactory(func(w io.Writer) Sink { return NewAVISink(w, DefaultAVIOptions) }))
+	RegisterSink("y4m", fileSinkFactory(func(w io.Writer) Sink { return NewY4MSink(w, nil) }))
+	RegisterSink("rgba", fileSinkFactory(func(w io.Writer) Sink { return NewRawSink(w, nil) }))
+	RegisterSink("animated-svg", fileSinkFactory(func(w io.Writer) Sink {
+		return NewAnimatedSVGSink(w, DefaultAnimatedSVGOptions)
+	}))
+}
+
+// fileSinkFactory returns factory of sinks writing to the output file,
+// "-" writes to stdout.
+func fileSinkFactory(newSink func(w io.Writer) Sink) SinkFactory {
+	return func(output string) (Sink, error) {
+		if output == "-" {
+			return NewFileSink(newSink(os.Stdout), nil), nil
+		}
+		if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+			return nil, err
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return nil, err
+		}
+		return NewFileSink(newSink(f), f), nil
+	}
+}
+
+// FileSink closes the file sink writes to after the sink is closed.
+type FileSink struct {
+	Sink
+	c io.Closer
+}
+
+// NewFileSink returns sink closing c after sink, c may be nil.
+func NewFileSink(sink Sink, c io.Closer) *FileSink {
+	return &FileSink{Sink: sink, c: c}
+}
+
+// FrameFormat returns the frame format of the wrapped sink.
+func (s *FileSink) FrameFormat() (ImageFormat, int) {
+	if f, ok := s.Sink.(FrameFormatter); ok {
+		return f.FrameFormat()
+	}
+	return FormatPNG, 0
+}
+
+// Close closes the sink and then the file.
+func (s *FileSink) Close() error {
+	err := s.Sink.Close()
+	if s.c == nil {
+		return err
+	}
+	if cerr := s.c.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// DirSink writes every frame to its own file named by a sprintf pattern
+// of the frame number, such as "render/%04d.png", or by a [FrameNamer].
+// Missing directories are created as needed and frames are written with
+// [CreateAtomic], so a frame file is never partial. WriteFrame is safe
+// for concurrent use.
+type DirSink struct {
+	name FrameNamer
+	dirs sync.Map
+}
+
+// NewDirSink returns sink naming frames with pattern, start is added to
+// zero based frame numbers.
+func NewDirSink(pattern string, start int) *DirSink {
+	return NewDirSinkFunc(func(f Frame) string { return fmt.Sprintf(pattern, start+f.Num) })
+}
+
+// NewDirSinkFunc returns sink naming frames with name.
+func NewDirSinkFunc(name FrameNamer) *DirSink {
+	return &DirSink{name: name}
+}
+
+// Open does nothing, directories are created as frames are written.
+func (s *DirSink) Open(Metadata) error {
+	return nil
+}
+
+// WriteFrame writes frame to the file.
+func (s *DirSink) WriteFrame(f Frame) error {
+	name := s.name(f)
+	dir := filepath.Dir(name)
+	if _, ok := s.dirs.Load(dir); !ok {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		s.dirs.Store(dir, true)
+	}
+	return writeFileAtomic(name, f.Data)
+}
+
+// Close does nothing since every frame is written immediately.
+func (s *DirSink) Close() error {
+	return nil
+}
+
+// atomicFile is written to a temporary file renamed to name on Close.
+type atomicFile struct {
+	*os.File
+	name string
+	err  error
+}
+
+// CreateAtomic creates the named file in a temporary file next to it
+// which replaces the file once closed, so readers never see partial
+// data and an interrupted write keeps the previous file. Nothing is
+// replaced if a write failed.
+func CreateAtomic(name string) (io.WriteCloser, error) {
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	if err = f.Chmod(0o644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &atomicFile{File: f, name: name}, nil
+}
+
+func (f *atomicFile) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	if err != nil && f.err == nil {
+		f.err = err
+	}
+	return n, err
+}
+
+func (f *atomicFile) Close() error {
+	err := f.File.Close()
+	if f.err != nil {
+		err = f.err
+	}
+	if err != nil {
+		os.Remove(f.File.Name())
+		return err
+	}
+	return os.Rename(f.File.Name(), f.name)
+}
+
+func writeFileAtomic(name string, data []byte) error {
+	f, err := CreateAtomic(name)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// MemorySink keeps frames in memory. It's safe for concurrent use.
+type MemorySink struct {
+	mu     sync.Mutex
+	meta   Metadata
+	frames []Frame
+}
+
+// NewMemorySink returns an empty in-memory sink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Open discards the frames kept before.
+func (s *MemorySink) Open(meta Metadata) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.meta, s.frames = meta, nil
+	return nil
+}
+
+// WriteFrame keeps frame.
+func (s *MemorySink) WriteFrame(f Frame) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.frames = append(s.frames, f)
+	return nil
+}
+
+// Close sorts the frames by number.
+func (s *MemorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sort.SliceStable(s.frames, func(i, j int) bool { return s.frames[i].Num < s.frames[j].Num })
+	return nil
+}
+
+// Metadata returns metadata the sink was opened with.
+func (s *MemorySink) Metadata() Metadata {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.meta
+}
+
+// Frames returns the written frames, sorted by number once the sink is closed.
+func (s *MemorySink) Frames() []Frame {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Frame(nil), s.frames...)
+}
+
+// WriterSink writes encoded frames one after another to w, such as
+// a PNG stream for ffmpeg -f image2pipe.
+type WriterSink struct {
+	w io.Writer
+}
+
+// NewWriterSink returns sink writing to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Open does nothing, frames are written as they come.
+func (s *WriterSink) Open(Metadata) error {
+	return nil
+}
+
+// WriteFrame writes frame data to w.
+func (s *WriterSink) WriteFrame(f Frame) error {
+	_, err := s.w.Write(f.Data)
+	return err
+}
+
+// Close does nothing, w is left open.
+func (s *WriterSink) Close() error {
+	return nil
+}
@@ -0,0 +1,103 @@
+package golottie
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFrames writes frames with data equal to their numbers in reverse order.
+func writeFrames(t *testing.T, sink Sink, nums ...int) {
+	require.NoError(t, sink.Open(Metadata{Width: 1, Height: 1, FramesTotal: len(nums)}))
+	for i := len(nums) - 1; i >= 0; i-- {
+		require.NoError(t, sink.WriteFrame(Frame{Num: nums[i], Data: []byte{byte(nums[i])}}))
+	}
+	require.NoError(t, sink.Close())
+}
+
+func Test_DirSink(t *testing.T) {
+	dir := t.TempDir()
+	writeFrames(t, NewDirSink(filepath.Join(dir, "frames", "%02d.png"), 1), 0, 1)
+	for name, want := range map[string]byte{"01.png": 0, "02.png": 1} {
+		data, err := os.ReadFile(filepath.Join(dir, "frames", name))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{want}, data)
+	}
+}
+
+func Test_CreateAtomic(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "manifest.json")
+	require.NoError(t, os.WriteFile(name, []byte("old"), 0o644))
+	w, err := CreateAtomic(name)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("new"))
+	require.NoError(t, err)
+	// the previous file is kept until the new one is closed
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old"), data)
+	require.NoError(t, w.Close())
+	data, err = os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), data)
+	entries, err := os.ReadDir(filepath.Dir(name))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func Test_MemorySink(t *testing.T) {
+	sink := NewMemorySink()
+	writeFrames(t, sink, 0, 1, 2)
+	assert.Equal(t, Metadata{Width: 1, Height: 1, FramesTotal: 3}, sink.Metadata())
+	assert.Equal(t, []Frame{{0, []byte{0}}, {1, []byte{1}}, {2, []byte{2}}}, sink.Frames())
+}
+
+func Test_WriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	writeFrames(t, NewWriterSink(&buf), 0, 1, 2)
+	assert.Equal(t, []byte{2, 1, 0}, buf.Bytes())
+}
+
+type closeRecorder struct{ closed bool }
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func Test_FileSink(t *testing.T) {
+	c := &closeRecorder{}
+	sink := NewFileSink(NewAnimatedSVGSink(io.Discard, DefaultAnimatedSVGOptions), c)
+	format, _ := sink.FrameFormat()
+	assert.Equal(t, FormatSVG, format)
+	require.NoError(t, sink.Open(Metadata{}))
+	assert.ErrorIs(t, sink.Close(), ErrNoFrames)
+	assert.True(t, c.closed)
+}
+
+func Test_NewSink(t *testing.T) {
+	assert.Subset(t, Sinks(), []string{"dir", "s3", "zip", "tar", "tar.gz", "stream", "gif", "apng", "webp", "avi", "y4m", "rgba", "animated-svg"})
+	_, err := NewSink("unknown", "out")
+	assert.ErrorIs(t, err, ErrUnknownSink)
+
+	memory := NewMemorySink()
+	RegisterSink("test-memory", func(string) (Sink, error) { return memory, nil })
+	sink, err := NewSink("test-memory", "")
+	require.NoError(t, err)
+	assert.Same(t, memory, sink)
+
+	output := filepath.Join(t.TempDir(), "out", "frames.zip")
+	sink, err = NewSink("zip", output)
+	require.NoError(t, err)
+	writeFrames(t, sink, 0)
+	zr, err := zip.OpenReader(output)
+	require.NoError(t, err)
+	defer zr.Close()
+	assert.Len(t, zr.File, 2)
+}
@@ -0,0 +1,441 @@
+package golottie
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SpriteLayout selects how frames are placed in the atlas.
+type SpriteLayout int
+
+const (
+	// SpriteGrid places frames in equally sized cells.
+	SpriteGrid SpriteLayout = iota
+	// SpritePacked packs frames into shelves sorted by height.
+	SpritePacked
+)
+
+// SpriteFormat is the sprite sheet metadata format.
+type SpriteFormat string
+
+const (
+	// SpriteJSONHash is TexturePacker JSON with frames keyed by name.
+	SpriteJSONHash SpriteFormat = "json-hash"
+	// SpriteJSONArray is TexturePacker JSON with frames listed in order.
+	SpriteJSONArray SpriteFormat = "json-array"
+	// SpriteCSS is a CSS keyframes animation stepping through the frames.
+	SpriteCSS SpriteFormat = "css"
+)
+
+// SpriteSheetOptions configures [SpriteSheetSink].
+type SpriteSheetOptions struct {
+	Layout SpriteLayout
+	// MaxSize limits atlas width and height, frames not fitting
+	// are placed into the next atlas.
+	MaxSize int
+	// Padding is the space between frames in pixels.
+	Padding int
+	// Trim removes transparent borders of the frames.
+	Trim bool
+	// Dedupe stores identical frames once.
+	Dedupe bool
+	// PowerOfTwo rounds atlas dimensions up to powers of two.
+	PowerOfTwo bool
+	// Formats are the metadata formats to write.
+	Formats []SpriteFormat
+}
+
+// DefaultSpriteSheetOptions packs trimmed and deduplicated frames
+// into 4096x4096 atlases describing them with JSON hash and CSS.
+var DefaultSpriteSheetOptions = SpriteSheetOptions{
+	Layout:  SpritePacked,
+	MaxSize: 4096,
+	Padding: 2,
+	Trim:    true,
+	Dedupe:  true,
+	Formats: []SpriteFormat{SpriteJSONHash, SpriteCSS},
+}
+
+// SpriteSheetSink packs rendered frames into atlas images written on
+// [SpriteSheetSink.Close] along with the metadata. A single atlas is
+// written as name.png and name.json, several atlases are numbered
+// name-0.png, name-0.json and so on. CSS is always written as name.css.
+type SpriteSheetSink struct {
+	create  CreateFunc
+	name    string
+	opts    SpriteSheetOptions
+	meta    Metadata
+	frames  []spriteFrame
+	sprites []*sprite
+	hashes  map[[sha256.Size]byte]*sprite
+}
+
+type sprite struct {
+	img   *image.NRGBA
+	atlas int
+	pos   image.Point
+}
+
+type spriteFrame struct {
+	num    int
+	sprite *sprite
+	// trim is the sprite location within the source frame
+	trim image.Rectangle
+}
+
+// NewSpriteSheetSink returns sprite sheet sink creating files named after
+// name with create.
+func NewSpriteSheetSink(create CreateFunc, name string, opts SpriteSheetOptions) *SpriteSheetSink {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultSpriteSheetOptions.MaxSize
+	}
+	if opts.Padding < 0 {
+		opts.Padding = 0
+	}
+	return &SpriteSheetSink{create: create, name: name, opts: opts}
+}
+
+// Open prepares the sink for the animation.
+func (s *SpriteSheetSink) Open(meta Metadata) error {
+	s.meta = meta
+	s.frames = s.frames[:0]
+	s.sprites = s.sprites[:0]
+	s.hashes = make(map[[sha256.Size]byte]*sprite)
+	return nil
+}
+
+// WriteFrame trims and stores PNG encoded frame.
+func (s *SpriteSheetSink) WriteFrame(f Frame) error {
+	src, err := png.Decode(bytes.NewReader(f.Data))
+	if err != nil {
+		return fmt.Errorf("error decoding frame %d: %w", f.Num, err)
+	}
+	b := src.Bounds()
+	if s.meta.Width <= 0 || s.meta.Height <= 0 {
+		s.meta.Width, s.meta.Height = b.Dx(), b.Dy()
+	}
+	trim := image.Rect(0, 0, s.meta.Width, s.meta.Height)
+	full := image.NewNRGBA(trim)
+	draw.Draw(full, trim, src, b.Min, draw.Src)
+	if s.opts.Trim {
+		trim = opaqueRect(full)
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, trim.Dx(), trim.Dy()))
+	draw.Draw(img, img.Rect, full, trim.Min, draw.Src)
+
+	var sp *sprite
+	if s.opts.Dedupe {
+		h := sha256.New()
+		fmt.Fprintf(h, "%dx%d", trim.Dx(), trim.Dy())
+		h.Write(img.Pix)
+		var key [sha256.Size]byte
+		copy(key[:], h.Sum(nil))
+		if sp = s.hashes[key]; sp == nil {
+			sp = &sprite{img: img}
+			s.hashes[key] = sp
+			s.sprites = append(s.sprites, sp)
+		}
+	} else {
+		sp = &sprite{img: img}
+		s.sprites = append(s.sprites, sp)
+	}
+	s.frames = append(s.frames, spriteFrame{num: f.Num, sprite: sp, trim: trim})
+	return nil
+}
+
+// Close packs the frames and writes atlases and metadata.
+func (s *SpriteSheetSink) Close() error {
+	if len(s.frames) == 0 {
+		return ErrNoFrames
+	}
+	sort.SliceStable(s.frames, func(i, j int) bool { return s.frames[i].num < s.frames[j].num })
+	sizes, err := s.pack()
+	if err != nil {
+		return err
+	}
+	atlases := make([]*image.NRGBA, len(sizes))
+	for i, size := range sizes {
+		atlases[i] = image.NewNRGBA(image.Rectangle{Max: size})
+	}
+	for _, sp := range s.sprites {
+		dst := sp.img.Rect.Add(sp.pos)
+		draw.Draw(atlases[sp.atlas], dst, sp.img, image.Point{}, draw.Src)
+	}
+
+	for i, atlas := range atlases {
+		var buf bytes.Buffer
+		if err = png.Encode(&buf, atlas); err != nil {
+			return err
+		}
+		if err = s.write(s.atlasName(i, len(atlases))+".png", buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	for _, format := range s.opts.Formats {
+		switch format {
+		case SpriteJSONHash, SpriteJSONArray:
+			for i := range atlases {
+				data, err := s.texturePacker(format, i, sizes)
+				if err != nil {
+					return err
+				}
+				if err = s.write(s.atlasName(i, len(atlases))+".json", data); err != nil {
+					return err
+				}
+			}
+		case SpriteCSS:
+			if err = s.write(s.name+".css", s.css(len(atlases))); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown sprite sheet format %q", format)
+		}
+	}
+	s.frames, s.sprites, s.hashes = nil, nil, nil
+	return nil
+}
+
+// pack places the sprites returning atlas sizes.
+func (s *SpriteSheetSink) pack() ([]image.Point, error) {
+	max := s.opts.MaxSize
+	pad := s.opts.Padding
+	cell := image.Point{}
+	area := 0
+	for _, sp := range s.sprites {
+		size := sp.img.Rect.Size()
+		if size.X > max || size.Y > max {
+			return nil, fmt.Errorf("error packing sprites: %dx%d frame exceeds %d atlas size", size.X, size.Y, max)
+		}
+		cell.X, cell.Y = maxInt(cell.X, size.X+pad), maxInt(cell.Y, size.Y+pad)
+		area += (size.X + pad) * (size.Y + pad)
+	}
+
+	var sizes []image.Point
+	place := func(sp *sprite, atlas int, pos image.Point) {
+		sp.atlas, sp.pos = atlas, pos
+		for len(sizes) <= atlas {
+			sizes = append(sizes, image.Point{})
+		}
+		end := sp.img.Rect.Size().Add(pos)
+		sizes[atlas].X, sizes[atlas].Y = maxInt(sizes[atlas].X, end.X), maxInt(sizes[atlas].Y, end.Y)
+	}
+
+	if s.opts.Layout == SpriteGrid {
+		cols := int(math.Ceil(math.Sqrt(float64(len(s.sprites)))))
+		cols = minInt(cols, maxInt(1, (max+pad)/cell.X))
+		rows := maxInt(1, (max+pad)/cell.Y)
+		for i, sp := range s.sprites {
+			n := i % (cols * rows)
+			place(sp, i/(cols*rows), image.Pt(n%cols*cell.X, n/cols*cell.Y))
+		}
+	} else {
+		width := minInt(max, maxInt(cell.X-pad, int(math.Ceil(math.Sqrt(float64(area))))))
+		order := make([]*sprite, len(s.sprites))
+		copy(order, s.sprites)
+		sort.SliceStable(order, func(i, j int) bool { return order[i].img.Rect.Dy() > order[j].img.Rect.Dy() })
+		atlas, x, y, shelf := 0, 0, 0, 0
+		for _, sp := range order {
+			size := sp.img.Rect.Size()
+			if x > 0 && x+size.X > width {
+				x, y, shelf = 0, y+shelf, 0
+			}
+			if y+size.Y > max {
+				atlas, x, y, shelf = atlas+1, 0, 0, 0
+			}
+			place(sp, atlas, image.Pt(x, y))
+			x += size.X + pad
+			shelf = maxInt(shelf, size.Y+pad)
+		}
+	}
+	if s.opts.PowerOfTwo {
+		for i := range sizes {
+			sizes[i] = image.Pt(nextPowerOfTwo(sizes[i].X), nextPowerOfTwo(sizes[i].Y))
+		}
+	}
+	return sizes, nil
+}
+
+func (s *SpriteSheetSink) atlasName(i, total int) string {
+	if total == 1 {
+		return s.name
+	}
+	return fmt.Sprintf("%s-%d", s.name, i)
+}
+
+func (s *SpriteSheetSink) frameName(num int) string {
+	return fmt.Sprintf("frame%04d", num)
+}
+
+// frameDuration returns frame duration in milliseconds.
+func (s *SpriteSheetSink) frameDuration(num int) int {
+	return s.meta.frameTime(num+1, 1000) - s.meta.frameTime(num, 1000)
+}
+
+type spriteRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type spriteSize struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type texturePackerFrame struct {
+	Filename         string     `json:"filename,omitempty"`
+	Frame            spriteRect `json:"frame"`
+	Rotated          bool       `json:"rotated"`
+	Trimmed          bool       `json:"trimmed"`
+	SpriteSourceSize spriteRect `json:"spriteSourceSize"`
+	SourceSize       spriteSize `json:"sourceSize"`
+	Duration         int        `json:"duration"`
+}
+
+type texturePackerMeta struct {
+	App     string     `json:"app"`
+	Version string     `json:"version"`
+	Image   string     `json:"image"`
+	Format  string     `json:"format"`
+	Size    spriteSize `json:"size"`
+	Scale   string     `json:"scale"`
+}
+
+// texturePacker returns TexturePacker JSON describing the atlas frames.
+func (s *SpriteSheetSink) texturePacker(format SpriteFormat, atlas int, sizes []image.Point) ([]byte, error) {
+	hash := make(map[string]texturePackerFrame)
+	array := make([]texturePackerFrame, 0)
+	for _, f := range s.frames {
+		if f.sprite.atlas != atlas {
+			continue
+		}
+		size := f.sprite.img.Rect.Size()
+		tf := texturePackerFrame{
+			Frame:            spriteRect{f.sprite.pos.X, f.sprite.pos.Y, size.X, size.Y},
+			Trimmed:          f.trim != image.Rect(0, 0, s.meta.Width, s.meta.Height),
+			SpriteSourceSize: spriteRect{f.trim.Min.X, f.trim.Min.Y, size.X, size.Y},
+			SourceSize:       spriteSize{s.meta.Width, s.meta.Height},
+			Duration:         s.frameDuration(f.num),
+		}
+		if format == SpriteJSONHash {
+			hash[s.frameName(f.num)] = tf
+			continue
+		}
+		tf.Filename = s.frameName(f.num)
+		array = append(array, tf)
+	}
+	var frames interface{} = hash
+	if format == SpriteJSONArray {
+		frames = array
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"frames": frames,
+		"meta": texturePackerMeta{
+			App:     "https://github.com/icyrogue/golottie",
+			Version: "1.0",
+			Image:   s.atlasName(atlas, len(sizes)) + ".png",
+			Format:  "RGBA8888",
+			Size:    spriteSize{sizes[atlas].X, sizes[atlas].Y},
+			Scale:   "1",
+		},
+	}, "", "  ")
+}
+
+var cssNameRe = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// css returns keyframes animation sizing and offsetting the element
+// for every frame, so trimmed frames stay in place.
+func (s *SpriteSheetSink) css(atlases int) []byte {
+	class := strings.Trim(cssNameRe.ReplaceAllString(s.name, "-"), "-")
+	if class == "" {
+		class = "sprite"
+	}
+	total := 0
+	for _, f := range s.frames {
+		total += s.frameDuration(f.num)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, ".%s {\n", class)
+	fmt.Fprintf(&b, "  width: %dpx;\n  height: %dpx;\n", s.meta.Width, s.meta.Height)
+	fmt.Fprintf(&b, "  background: url(%q) no-repeat;\n", s.atlasName(0, atlases)+".png")
+	fmt.Fprintf(&b, "  animation: %s %gs step-end infinite;\n}\n\n", class, float64(total)/1000)
+	fmt.Fprintf(&b, "@keyframes %s {\n", class)
+	elapsed := 0
+	for _, f := range s.frames {
+		size := f.sprite.img.Rect.Size()
+		fmt.Fprintf(&b, "  %.3f%% {", float64(elapsed)*100/float64(total))
+		if atlases > 1 {
+			fmt.Fprintf(&b, " background-image: url(%q);", s.atlasName(f.sprite.atlas, atlases)+".png")
+		}
+		fmt.Fprintf(&b, " background-position: %dpx %dpx;", -f.sprite.pos.X, -f.sprite.pos.Y)
+		fmt.Fprintf(&b, " width: %dpx; height: %dpx;", size.X, size.Y)
+		fmt.Fprintf(&b, " transform: translate(%dpx, %dpx); }\n", f.trim.Min.X, f.trim.Min.Y)
+		elapsed += s.frameDuration(f.num)
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+func (s *SpriteSheetSink) write(name string, data []byte) error {
+	w, err := s.create(name)
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// opaqueRect returns bounds of non transparent pixels,
+// a single pixel for fully transparent images.
+func opaqueRect(img *image.NRGBA) image.Rectangle {
+	b := img.Bounds()
+	r := image.Rectangle{Min: b.Max, Max: b.Min}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.Pix[img.PixOffset(x, y)+3] == 0 {
+				continue
+			}
+			r.Min.X, r.Min.Y = minInt(r.Min.X, x), minInt(r.Min.Y, y)
+			r.Max.X, r.Max.Y = maxInt(r.Max.X, x+1), maxInt(r.Max.Y, y+1)
+		}
+	}
+	if r.Empty() {
+		return image.Rect(b.Min.X, b.Min.Y, b.Min.X+1, b.Min.Y+1)
+	}
+	return r
+}
+
+func nextPowerOfTwo(v int) int {
+	p := 1
+	for p < v {
+		p <<= 1
+	}
+	return p
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,120 @@
+package golottie
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memFile struct {
+	bytes.Buffer
+	files map[string][]byte
+	name  string
+}
+
+func (f *memFile) Close() error {
+	f.files[f.name] = f.Bytes()
+	return nil
+}
+
+func memCreate(files map[string][]byte) CreateFunc {
+	return func(name string) (io.WriteCloser, error) {
+		return &memFile{files: files, name: name}, nil
+	}
+}
+
+// dotFrame encodes transparent 8x8 frame with a 2x2 square at x, y.
+func dotFrame(t *testing.T, x, y int, c color.NRGBA) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	draw.Draw(img, image.Rect(x, y, x+2, y+2), image.NewUniform(c), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func Test_SpriteSheetSink(t *testing.T) {
+	red, blue := color.NRGBA{R: 255, A: 255}, color.NRGBA{B: 255, A: 255}
+	frames := [][]byte{dotFrame(t, 1, 1, red), dotFrame(t, 4, 5, red), dotFrame(t, 0, 0, blue)}
+	tests := []struct {
+		name    string
+		opts    SpriteSheetOptions
+		files   []string
+		sizes   []image.Point
+		sprites map[string][4]int // frame x, y, w, h in the atlas
+	}{
+		{
+			name:  "packed, trimmed and deduplicated",
+			opts:  DefaultSpriteSheetOptions,
+			files: []string{"sheet.png", "sheet.json", "sheet.css"},
+			sizes: []image.Point{{6, 2}},
+			sprites: map[string][4]int{
+				"frame0000": {0, 0, 2, 2},
+				"frame0001": {0, 0, 2, 2},
+				"frame0002": {4, 0, 2, 2},
+			},
+		},
+		{
+			name:  "grid split into atlases",
+			opts:  SpriteSheetOptions{Layout: SpriteGrid, MaxSize: 8, PowerOfTwo: true, Formats: []SpriteFormat{SpriteJSONArray}},
+			files: []string{"sheet-0.png", "sheet-0.json", "sheet-1.png", "sheet-1.json", "sheet-2.png", "sheet-2.json"},
+			sizes: []image.Point{{8, 8}, {8, 8}, {8, 8}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make(map[string][]byte)
+			sink := NewSpriteSheetSink(memCreate(files), "sheet", tt.opts)
+			assert.NoError(t, sink.Open(Metadata{Width: 8, Height: 8, FrameRate: 30}))
+			for i, f := range frames {
+				assert.NoError(t, sink.WriteFrame(Frame{Num: i, Data: f}))
+			}
+			assert.NoError(t, sink.Close())
+			assert.Len(t, files, len(tt.files))
+			for i, name := range tt.files {
+				assert.Contains(t, files, name)
+				if strings.HasSuffix(name, ".png") {
+					img, err := png.Decode(bytes.NewReader(files[name]))
+					assert.NoError(t, err)
+					assert.Equal(t, tt.sizes[i/2], img.Bounds().Size())
+				}
+			}
+			if tt.sprites == nil {
+				return
+			}
+
+			var meta struct {
+				Frames map[string]texturePackerFrame
+				Meta   texturePackerMeta
+			}
+			assert.NoError(t, json.Unmarshal(files["sheet.json"], &meta))
+			assert.Equal(t, "sheet.png", meta.Meta.Image)
+			for name, r := range tt.sprites {
+				f := meta.Frames[name]
+				assert.Equal(t, spriteRect{r[0], r[1], r[2], r[3]}, f.Frame, name)
+				assert.True(t, f.Trimmed)
+				assert.Equal(t, spriteSize{8, 8}, f.SourceSize)
+			}
+			assert.Equal(t, spriteRect{4, 5, 2, 2}, meta.Frames["frame0001"].SpriteSourceSize)
+			assert.Equal(t, 34, meta.Frames["frame0001"].Duration)
+
+			css := string(files["sheet.css"])
+			assert.Contains(t, css, "animation: sheet 0.1s step-end infinite;")
+			assert.Contains(t, css, "33.000% { background-position: 0px 0px; width: 2px; height: 2px; transform: translate(4px, 5px); }")
+		})
+	}
+}
+
+func Test_SpriteSheetSink_TooLarge(t *testing.T) {
+	sink := NewSpriteSheetSink(memCreate(map[string][]byte{}), "sheet", SpriteSheetOptions{MaxSize: 4})
+	assert.NoError(t, sink.Open(Metadata{}))
+	assert.NoError(t, sink.WriteFrame(Frame{Data: dotFrame(t, 0, 0, color.NRGBA{A: 255})}))
+	assert.Error(t, sink.Close())
+}
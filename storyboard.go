@@ -0,0 +1,187 @@
+package golottie
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+)
+
+// StoryboardOptions configures [NewStoryboard].
+type StoryboardOptions struct {
+	// Columns of the grid, frames are laid out in a square grid if 0.
+	Columns int
+	// CellWidth is the width frames are scaled to.
+	CellWidth int
+	// Padding between the cells in pixels.
+	Padding int
+	// Background of the storyboard, frames are drawn over it.
+	Background color.Color
+	// Labels are extra labels per frame number, such as marker names.
+	Labels map[int]string
+}
+
+// DefaultStoryboardOptions lays frames out on white background
+// scaling them to 320 pixels wide.
+var DefaultStoryboardOptions = StoryboardOptions{
+	CellWidth:  320,
+	Padding:    16,
+	Background: color.White,
+}
+
+// storyboard label colors.
+var (
+	storyboardText   = color.NRGBA{0x33, 0x33, 0x33, 0xff}
+	storyboardBorder = color.NRGBA{0xcc, 0xcc, 0xcc, 0xff}
+)
+
+const storyboardTextScale = 2
+
+// SampleFrames returns n evenly spaced frames including the first
+// and the last one.
+func SampleFrames(framesTotal, n int) []int {
+	if framesTotal <= 0 || n <= 0 {
+		return nil
+	}
+	if n >= framesTotal {
+		n = framesTotal
+	}
+	if n == 1 {
+		return []int{0}
+	}
+	frames := make([]int, n)
+	for i := range frames {
+		frames[i] = int(math.Round(float64(i) * float64(framesTotal-1) / float64(n-1)))
+	}
+	return frames
+}
+
+// MarkerFrames returns frames the animation markers start at along
+// with the marker names keyed by frame.
+func MarkerFrames(data []byte) ([]int, map[int]string, error) {
+	c, err := ParseComposition(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	labels := make(map[int]string)
+	var frames []int
+	for _, m := range c.Markers {
+		frame := int(math.Round(m.Time - c.InPoint))
+		if frame < 0 || float64(frame) >= c.OutPoint-c.InPoint {
+			continue
+		}
+		if name, ok := labels[frame]; ok {
+			labels[frame] = name + ", " + m.Comment
+			continue
+		}
+		labels[frame] = m.Comment
+		frames = append(frames, frame)
+	}
+	sort.Ints(frames)
+	return frames, labels, nil
+}
+
+// RenderFrames renders the provided frames as PNG, the renderer
+// continues from the frame after the last one.
+func (r *Renderer) RenderFrames(frames []int) ([]Frame, error) {
+	rendered := make([]Frame, 0, len(frames))
+	for _, n := range frames {
+		if err := r.GoToFrame(n); err != nil {
+			return rendered, err
+		}
+		var buf []byte
+		if err := r.RenderFrame(&buf); err != nil {
+			return rendered, err
+		}
+		rendered = append(rendered, Frame{Num: n, Data: buf})
+	}
+	return rendered, nil
+}
+
+// NewStoryboard lays PNG encoded frames out in a grid labeling each
+// of them with the frame number, timestamp and the extra label if any.
+// The animation name is drawn on top.
+//
+// Example:
+//
+//	frames, err := renderer.RenderFrames(golottie.SampleFrames(animation.GetFramesTotal(), 12))
+//	...
+//	img, err := golottie.NewStoryboard(animation.Metadata(), frames, golottie.DefaultStoryboardOptions)
+//	...
+//	png.Encode(f, img)
+func NewStoryboard(meta Metadata, frames []Frame, opts StoryboardOptions) (*image.NRGBA, error) {
+	if len(frames) == 0 {
+		return nil, ErrNoFrames
+	}
+	if opts.CellWidth <= 0 {
+		opts.CellWidth = DefaultStoryboardOptions.CellWidth
+	}
+	if opts.Padding < 0 {
+		opts.Padding = 0
+	}
+	if opts.Background == nil {
+		opts.Background = DefaultStoryboardOptions.Background
+	}
+	cols := opts.Columns
+	if cols <= 0 {
+		cols = int(math.Ceil(math.Sqrt(float64(len(frames)))))
+	}
+	if cols > len(frames) {
+		cols = len(frames)
+	}
+	rows := (len(frames) + cols - 1) / cols
+
+	images := make([]*image.NRGBA, len(frames))
+	for i, f := range frames {
+		img, err := decodeNRGBA(f.Data)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding frame %d: %w", f.Num, err)
+		}
+		images[i] = img
+	}
+	w, h := meta.Width, meta.Height
+	if w <= 0 || h <= 0 {
+		w, h = images[0].Rect.Dx(), images[0].Rect.Dy()
+	}
+	cellW, cellH := fitSize(w, h, opts.CellWidth)
+	lineH := (glyphHeight + 3) * storyboardTextScale
+	labelH := 2 * lineH
+	titleH := 0
+	if meta.Name != "" {
+		titleH = lineH*2 + opts.Padding
+	}
+	pad := opts.Padding
+	sheet := image.NewNRGBA(image.Rect(0, 0,
+		cols*cellW+(cols+1)*pad,
+		titleH+rows*(cellH+labelH)+(rows+1)*pad))
+	draw.Draw(sheet, sheet.Rect, image.NewUniform(opts.Background), image.Point{}, draw.Src)
+	if meta.Name != "" {
+		drawText(sheet, pad, pad, truncateText(meta.Name, storyboardTextScale*2, sheet.Rect.Dx()-2*pad), storyboardText, storyboardTextScale*2)
+	}
+
+	for i, f := range frames {
+		x := pad + i%cols*(cellW+pad)
+		y := titleH + pad + i/cols*(cellH+labelH+pad)
+		cell := image.Rect(x, y, x+cellW, y+cellH)
+		draw.Draw(sheet, cell.Inset(-1), image.NewUniform(storyboardBorder), image.Point{}, draw.Src)
+		draw.Draw(sheet, cell, image.NewUniform(opts.Background), image.Point{}, draw.Src)
+		draw.Draw(sheet, cell, resizeImage(images[i], cellW, cellH), image.Point{}, draw.Over)
+
+		label := fmt.Sprintf("#%d  %s", f.Num, formatTimestamp(f.Num, meta.FrameRate))
+		drawText(sheet, x, y+cellH+storyboardTextScale*2, truncateText(label, storyboardTextScale, cellW), storyboardText, storyboardTextScale)
+		if extra := opts.Labels[f.Num]; extra != "" {
+			drawText(sheet, x, y+cellH+storyboardTextScale*2+lineH, truncateText(extra, storyboardTextScale, cellW), storyboardText, storyboardTextScale)
+		}
+	}
+	return sheet, nil
+}
+
+// formatTimestamp returns frame time as seconds with two decimals.
+func formatTimestamp(frame int, frameRate float64) string {
+	if frameRate <= 0 {
+		return "0.00s"
+	}
+	return fmt.Sprintf("%.2fs", float64(frame)/frameRate)
+}
@@ -0,0 +1,79 @@
+package golottie
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var markerAnimData = []byte(`{"v":"5.7.4","nm":"markers","fr":30,"ip":10,"op":70,"w":100,"h":50,"layers":[],
+"markers":[{"cm":"intro","tm":10,"dr":20},{"cm":"loop","tm":40,"dr":30},{"cm":"also loop","tm":40,"dr":0},{"cm":"tail","tm":69,"dr":0},
+{"cm":"after out point","tm":75,"dr":0},{"cm":"outside","tm":200,"dr":0}]}`)
+
+func Test_SampleFrames(t *testing.T) {
+	assert.Equal(t, []int{0, 20, 39, 59}, SampleFrames(60, 4))
+	assert.Equal(t, []int{0, 1, 2}, SampleFrames(3, 10))
+	assert.Equal(t, []int{0}, SampleFrames(60, 1))
+	assert.Nil(t, SampleFrames(0, 4))
+}
+
+func Test_MarkerFrames(t *testing.T) {
+	frames, labels, err := MarkerFrames(markerAnimData)
+	assert.NoError(t, err)
+	// markers are relative to the in point, the out point is excluded
+	assert.Equal(t, []int{0, 30, 59}, frames)
+	assert.Equal(t, map[int]string{0: "intro", 30: "loop, also loop", 59: "tail"}, labels)
+}
+
+func Test_NewStoryboard(t *testing.T) {
+	frame := func(c color.NRGBA) []byte {
+		img := image.NewNRGBA(image.Rect(0, 0, 100, 50))
+		for i := 0; i < len(img.Pix); i += 4 {
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+		}
+		var buf bytes.Buffer
+		assert.NoError(t, png.Encode(&buf, img))
+		return buf.Bytes()
+	}
+	frames := []Frame{
+		{Num: 0, Data: frame(color.NRGBA{R: 255, A: 255})},
+		{Num: 15, Data: frame(color.NRGBA{G: 255, A: 255})},
+		{Num: 30, Data: frame(color.NRGBA{B: 255, A: 255})},
+	}
+	opts := DefaultStoryboardOptions
+	opts.CellWidth = 50
+	opts.Padding = 10
+	opts.Labels = map[int]string{30: "loop"}
+	img, err := NewStoryboard(Metadata{Name: "markers", Width: 100, Height: 50, FrameRate: 30}, frames, opts)
+	assert.NoError(t, err)
+
+	// two columns, two rows of 25px cells with 40px labels under a 50px title
+	lineH := (glyphHeight + 3) * storyboardTextScale
+	assert.Equal(t, image.Pt(2*50+3*10, 2*lineH+10+2*(25+2*lineH)+3*10), img.Rect.Size())
+	titleH := 2*lineH + 10
+	assert.Equal(t, color.NRGBA{R: 255, A: 255}, img.NRGBAAt(10+25, titleH+10+12))
+	assert.Equal(t, color.NRGBA{G: 255, A: 255}, img.NRGBAAt(70+25, titleH+10+12))
+	assert.Equal(t, color.NRGBA{B: 255, A: 255}, img.NRGBAAt(10+25, titleH+10+25+2*lineH+10+12))
+	// label text is drawn below the cell
+	labelRow := img.SubImage(image.Rect(10, titleH+10+25, 60, titleH+10+25+lineH)).(*image.NRGBA)
+	assert.Contains(t, string(labelRow.Pix), string([]byte{0x33, 0x33, 0x33, 0xff}))
+
+	_, err = NewStoryboard(Metadata{}, nil, opts)
+	assert.ErrorIs(t, err, ErrNoFrames)
+}
+
+func Test_resizeImage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 2; x++ {
+		for y := 0; y < 2; y++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	dst := resizeImage(src, 2, 1)
+	assert.Equal(t, color.NRGBA{R: 200, A: 255}, dst.NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{}, dst.NRGBAAt(1, 0))
+}
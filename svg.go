@@ -0,0 +1,260 @@
+package golottie
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	svgIDRe   = regexp.MustCompile(`\bid="([^"]+)"`)
+	svgURLRe  = regexp.MustCompile(`url\((['"]?)#([^)'"]+)(['"]?)\)`)
+	svgHrefRe = regexp.MustCompile(`\b((?:xlink:)?href)="#([^"]+)"`)
+
+	svgRootRe     = regexp.MustCompile(`^\s*<svg\b([^>]*)>`)
+	svgAttrRe     = regexp.MustCompile(`([\w:-]+)="([^"]*)"`)
+	svgImageRe    = regexp.MustCompile(`<image\b[^>]*>`)
+	svgPathHrefRe = regexp.MustCompile(`\b((?:xlink:)?href)="([^"#][^"]*)"`)
+	svgTextRe     = regexp.MustCompile(`(?s)<text\b[^>]*>(.*?)</text>`)
+	svgTagRe      = regexp.MustCompile(`<[^>]*>`)
+	svgFamilyRe   = regexp.MustCompile(`font-family(?:="|:\s*)([^";]+)`)
+	svgCommentRe  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	svgSpaceRe    = regexp.MustCompile(`>\s+<`)
+	svgNumberRe   = regexp.MustCompile(`-?\d+\.\d{4,}`)
+)
+
+// svgXMLHeader starts every standalone SVG document.
+const svgXMLHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+// SVGOptions configures [StandaloneSVG].
+type SVGOptions struct {
+	// IDPrefix is prepended to element ids and references to them.
+	IDPrefix string
+	// Assets resolves image paths of the animation, external images
+	// are kept as is if it's nil.
+	Assets fs.FS
+	// Fonts is the font file data keyed by the font family. Fonts used
+	// by text elements are embedded, TrueType fonts are subset to the
+	// characters of the text.
+	Fonts map[string][]byte
+	// Minify strips comments and whitespace between tags outside text
+	// elements and rounds numbers in attributes to three decimals.
+	Minify bool
+}
+
+// StandaloneSVG turns SVG markup serialized by the browser into a valid
+// standalone SVG document sized to the animation. External images are
+// embedded as data URIs and the used fonts as @font-face rules, so the
+// document doesn't depend on the player page.
+//
+// Example:
+//
+//	var svg string
+//	err := renderer.RenderFrameSVG(&svg)
+//	...
+//	doc, err := golottie.StandaloneSVG(svg, animation.Metadata(), golottie.SVGOptions{
+//		Assets: os.DirFS("."),
+//		Minify: true,
+//	})
+func StandaloneSVG(svg string, meta Metadata, opts SVGOptions) (string, error) {
+	root := svgRootRe.FindStringSubmatchIndex(svg)
+	if root == nil {
+		return "", fmt.Errorf("error converting svg: root svg element not found")
+	}
+	// html serialization may contain entities unknown to xml
+	body := strings.ReplaceAll(svg[root[1]:], "&nbsp;", "&#160;")
+
+	var err error
+	if opts.Assets != nil {
+		if body, err = embedSVGImages(body, opts.Assets); err != nil {
+			return "", err
+		}
+	}
+	style, err := svgFontFaces(body, opts.Fonts)
+	if err != nil {
+		return "", err
+	}
+	if style != "" {
+		body = "<defs><style>" + style + "</style></defs>" + body
+	}
+	if opts.IDPrefix != "" {
+		body = prefixSVGIDs(body, opts.IDPrefix)
+	}
+	if opts.Minify {
+		body = svgCommentRe.ReplaceAllString(body, "")
+		body = minifySVGSpace(body)
+		body = svgAttrRe.ReplaceAllStringFunc(body, func(attr string) string {
+			// text content and data URIs are left intact
+			if strings.Contains(attr, "data:") {
+				return attr
+			}
+			return svgNumberRe.ReplaceAllStringFunc(attr, func(n string) string {
+				f, err := strconv.ParseFloat(n, 64)
+				if err != nil {
+					return n
+				}
+				// avoid "-0" for small negative numbers
+				return strconv.FormatFloat(math.Round(f*1000)/1000+0, 'f', -1, 64)
+			})
+		})
+	}
+
+	var b strings.Builder
+	b.WriteString(svgXMLHeader)
+	b.WriteString(svgRootTag(svg[root[2]:root[3]], meta, strings.Contains(body, "xlink:")))
+	b.WriteString(strings.TrimSpace(body))
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// minifySVGSpace strips whitespace between tags, except inside text
+// elements where it separates the words of tspans.
+func minifySVGSpace(svg string) string {
+	var b strings.Builder
+	prev := 0
+	for _, loc := range svgTextRe.FindAllStringIndex(svg, -1) {
+		// keep the brackets of the text element so the whitespace
+		// around it is still stripped
+		b.WriteString(svgSpaceRe.ReplaceAllString(svg[prev:loc[0]+1], "><"))
+		b.WriteString(svg[loc[0]+1 : loc[1]-1])
+		prev = loc[1] - 1
+	}
+	b.WriteString(svgSpaceRe.ReplaceAllString(svg[prev:], "><"))
+	return b.String()
+}
+
+// svgRootTag rebuilds the root element with the namespaces, viewBox and
+// size of the animation, dropping the inline styles set by the player.
+func svgRootTag(attrs string, meta Metadata, xlink bool) string {
+	var b strings.Builder
+	b.WriteString(`<svg xmlns="http://www.w3.org/2000/svg"`)
+	if xlink {
+		b.WriteString(` xmlns:xlink="http://www.w3.org/1999/xlink"`)
+	}
+	viewBox := fmt.Sprintf("0 0 %d %d", meta.Width, meta.Height)
+	var rest strings.Builder
+	for _, m := range svgAttrRe.FindAllStringSubmatch(attrs, -1) {
+		switch m[1] {
+		case "xmlns", "xmlns:xlink", "width", "height", "style":
+		case "viewBox":
+			if meta.Width <= 0 || meta.Height <= 0 {
+				viewBox = m[2]
+			}
+		default:
+			fmt.Fprintf(&rest, ` %s="%s"`, m[1], m[2])
+		}
+	}
+	fmt.Fprintf(&b, ` viewBox="%s"`, viewBox)
+	if meta.Width > 0 && meta.Height > 0 {
+		fmt.Fprintf(&b, ` width="%d" height="%d"`, meta.Width, meta.Height)
+	}
+	b.WriteString(rest.String())
+	b.WriteString(">")
+	return b.String()
+}
+
+// embedSVGImages replaces image paths with data URIs of the assets.
+func embedSVGImages(svg string, assets fs.FS) (string, error) {
+	var err error
+	embed := func(attr string) string {
+		m := svgPathHrefRe.FindStringSubmatch(attr)
+		href := html.UnescapeString(m[2])
+		if err != nil || strings.HasPrefix(href, "data:") || strings.Contains(href, "://") {
+			return attr
+		}
+		name := path.Clean(strings.TrimPrefix(href, "/"))
+		data, readErr := fs.ReadFile(assets, name)
+		if readErr != nil {
+			err = fmt.Errorf("error embedding svg image %q: %w", href, readErr)
+			return attr
+		}
+		return m[1] + `="` + dataURI(name, data) + `"`
+	}
+	svg = svgImageRe.ReplaceAllStringFunc(svg, func(tag string) string {
+		return svgPathHrefRe.ReplaceAllStringFunc(tag, embed)
+	})
+	return svg, err
+}
+
+// svgFontFaces returns @font-face rules for the fonts used by text
+// elements, fonts are subset to the text characters.
+func svgFontFaces(svg string, fonts map[string][]byte) (string, error) {
+	if len(fonts) == 0 {
+		return "", nil
+	}
+	var text strings.Builder
+	for _, m := range svgTextRe.FindAllStringSubmatch(svg, -1) {
+		text.WriteString(html.UnescapeString(svgTagRe.ReplaceAllString(m[1], "")))
+	}
+	if text.Len() == 0 {
+		return "", nil
+	}
+	used := make(map[string]bool)
+	for _, m := range svgFamilyRe.FindAllStringSubmatch(svg, -1) {
+		for _, family := range strings.Split(html.UnescapeString(m[1]), ",") {
+			used[strings.Trim(strings.TrimSpace(family), `'"`)] = true
+		}
+	}
+	families := make([]string, 0, len(fonts))
+	for family := range fonts {
+		if used[family] {
+			families = append(families, family)
+		}
+	}
+	sort.Strings(families)
+	var b strings.Builder
+	for _, family := range families {
+		data, err := subsetFont(fonts[family], text.String())
+		if err != nil {
+			return "", fmt.Errorf("error embedding font %q: %w", family, err)
+		}
+		fmt.Fprintf(&b, `@font-face{font-family:'%s';src:url(data:%s;base64,%s)}`,
+			strings.ReplaceAll(family, "'", `\'`), fontMIMEType(data), base64.StdEncoding.EncodeToString(data))
+	}
+	return b.String(), nil
+}
+
+// prefixSVGIDs prefixes element ids and references to them, so several
+// frames can be placed in a single document without id clashes.
+func prefixSVGIDs(svg, prefix string) string {
+	return renameSVGIDs(svg, func(id string) string { return prefix + id })
+}
+
+// renameSVGIDs renames element ids and references to them.
+func renameSVGIDs(svg string, rename func(id string) string) string {
+	svg = svgIDRe.ReplaceAllStringFunc(svg, func(s string) string {
+		m := svgIDRe.FindStringSubmatch(s)
+		return `id="` + rename(m[1]) + `"`
+	})
+	svg = svgURLRe.ReplaceAllStringFunc(svg, func(s string) string {
+		m := svgURLRe.FindStringSubmatch(s)
+		return "url(" + m[1] + "#" + rename(m[2]) + m[3] + ")"
+	})
+	return svgHrefRe.ReplaceAllStringFunc(svg, func(s string) string {
+		m := svgHrefRe.FindStringSubmatch(s)
+		return m[1] + `="#` + rename(m[2]) + `"`
+	})
+}
+
+// RenderFrameStandaloneSVG renders current frame as a standalone SVG
+// document, see [StandaloneSVG]. Element ids are prefixed with the frame
+// number unless [SVGOptions.IDPrefix] is set, so they are unique per frame.
+func (r *Renderer) RenderFrameStandaloneSVG(frameBuf *string, opts SVGOptions) error {
+	var svg string
+	if err := r.RenderFrameSVG(&svg); err != nil {
+		return err
+	}
+	if opts.IDPrefix == "" {
+		opts.IDPrefix = fmt.Sprintf("f%d-", r.framesDone-1)
+	}
+	var err error
+	*frameBuf, err = StandaloneSVG(svg, Metadata{Width: r.width, Height: r.height}, opts)
+	return err
+}
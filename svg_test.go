@@ -0,0 +1,63 @@
+package golottie
+
+import (
+	"encoding/xml"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_prefixSVGIDs(t *testing.T) {
+	svg := `<svg><defs><clipPath id="__lottie_element_2"><rect/></clipPath><g id="m"/></defs>` +
+		`<g clip-path="url(#__lottie_element_2)" mask="url('#m')"><use xlink:href="#m" href="#m"/></g></svg>`
+	assert.Equal(t,
+		`<svg><defs><clipPath id="f1-__lottie_element_2"><rect/></clipPath><g id="f1-m"/></defs>`+
+			`<g clip-path="url(#f1-__lottie_element_2)" mask="url('#f1-m')"><use xlink:href="#f1-m" href="#f1-m"/></g></svg>`,
+		prefixSVGIDs(svg, "f1-"))
+}
+
+func Test_StandaloneSVG(t *testing.T) {
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 100 50" width="100%" height="100%" ` +
+		`preserveAspectRatio="xMidYMid meet" style="width: 100%; height: 100%; transform: translate3d(0px, 0px, 0px);">` +
+		"\n  <!-- comment --><defs><clipPath id=\"c\"><rect width=\"100\" height=\"50\"></rect></clipPath></defs>\n" +
+		`<g clip-path="url(#c)"><image href="images/img_0.png" xlink:href="images/img_0.png"></image>` +
+		`<path d="M1.123756,2 L3.5,-0.0001"></path>` + "\n" +
+		`<text font-family="Test Sans">A&nbsp;C 1.234567 <tspan>B</tspan> <tspan>D</tspan></text>` + "\n</g></svg>"
+	assets := fstest.MapFS{"images/img_0.png": {Data: []byte("\x89PNG\r\n\x1a\n")}}
+	fonts := map[string][]byte{"Test Sans": testFont(), "Unused": testFont()}
+	doc, err := StandaloneSVG(svg, Metadata{Width: 100, Height: 50}, SVGOptions{
+		IDPrefix: "f3-",
+		Assets:   assets,
+		Fonts:    fonts,
+		Minify:   true,
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(doc, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 100 50" width="100" height="50" preserveAspectRatio="xMidYMid meet"><defs><style>@font-face{font-family:'Test Sans';src:url(data:font/ttf;base64,`))
+	assert.Equal(t, 1, strings.Count(doc, "@font-face"))
+	assert.Contains(t, doc, `<defs><clipPath id="f3-c">`)
+	assert.Contains(t, doc, `clip-path="url(#f3-c)"`)
+	assert.Contains(t, doc, `href="data:image/png;base64,iVBORw0KGgo="`)
+	assert.NotContains(t, doc, "images/img_0.png")
+	assert.Contains(t, doc, `d="M1.124,2 L3.5,0"></path><text`)
+	assert.Contains(t, doc, `A&#160;C 1.234567 <tspan>B</tspan> <tspan>D</tspan></text></g>`)
+	assert.NotContains(t, doc, "comment")
+	d := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	_, err = StandaloneSVG(strings.Replace(svg, "img_0", "img_1", 1), Metadata{Width: 100, Height: 50}, SVGOptions{Assets: assets})
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = StandaloneSVG("<div></div>", Metadata{}, SVGOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package golottie
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Telegram sticker constraints.
+const (
+	TGSSize         = 512
+	TGSMaxFrameRate = 60
+	TGSMaxDuration  = 3.0
+	TGSMaxBytes     = 64 * 1024
+)
+
+// IsTGS reports whether data looks like a gzip compressed Telegram sticker.
+func IsTGS(data []byte) bool {
+	return bytes.HasPrefix(data, []byte{0x1f, 0x8b})
+}
+
+// DecodeTGS decompresses Telegram sticker into Lottie JSON.
+// [NewAnimation] decodes stickers transparently.
+func DecodeTGS(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding tgs: %w", err)
+	}
+	defer r.Close()
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding tgs: %w", err)
+	}
+	return decoded, nil
+}
+
+// EncodeTGS compresses Lottie JSON into a Telegram sticker
+// marking the animation with the "tgs" field.
+// Use [CheckTGS] to make sure the sticker meets Telegram constraints.
+func EncodeTGS(data []byte) ([]byte, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("error encoding tgs: %w", err)
+	}
+	root["tgs"] = 1
+	data, err := json.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding tgs: %w", err)
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CheckTGS reports every Telegram sticker constraint violated by the animation.
+// Data can be either a sticker or Lottie JSON, in which case the compressed size
+// is checked as if the data was encoded with [EncodeTGS].
+//
+// Example:
+//
+//	data, _ := os.ReadFile("sticker.tgs")
+//	report, err := golottie.CheckTGS(data)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	report.WriteText(os.Stdout)
+func CheckTGS(data []byte) (*Report, error) {
+	compressed := data
+	var err error
+	if IsTGS(data) {
+		if data, err = DecodeTGS(data); err != nil {
+			return nil, err
+		}
+	} else if compressed, err = EncodeTGS(data); err != nil {
+		return nil, err
+	}
+	c, err := ParseComposition(data)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	violation := func(code, layer, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, Issue{
+			Severity: SeverityError,
+			Code:     code,
+			Layer:    layer,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+	if c.Width != TGSSize || c.Height != TGSSize {
+		violation("tgs-size", "", "dimensions %gx%g must be %dx%d", c.Width, c.Height, TGSSize, TGSSize)
+	}
+	if c.FrameRate > TGSMaxFrameRate {
+		violation("tgs-frame-rate", "", "frame rate %g exceeds %d fps", c.FrameRate, TGSMaxFrameRate)
+	}
+	if d := c.Duration(); d > TGSMaxDuration {
+		violation("tgs-duration", "", "duration %.3fs exceeds %gs", d, TGSMaxDuration)
+	}
+	if len(compressed) > TGSMaxBytes {
+		violation("tgs-bytes", "", "compressed size %d bytes exceeds %d bytes", len(compressed), TGSMaxBytes)
+	}
+	for _, asset := range c.Assets {
+		if !asset.IsPrecomp() {
+			violation("tgs-image", "", "image asset %q is not allowed", asset.ID)
+		}
+	}
+	for _, layer := range NewInfo(c).Layers {
+		checkTGSLayer(layer, "", violation)
+	}
+	var root map[string]interface{}
+	if err = json.Unmarshal(data, &root); err == nil {
+		if tgs, _ := root["tgs"].(float64); tgs != 1 {
+			report.Issues = append(report.Issues, Issue{
+				Severity: SeverityWarning,
+				Code:     "tgs-flag",
+				Message:  `missing "tgs": 1 field, EncodeTGS adds it`,
+			})
+		}
+	}
+	return report, nil
+}
+
+func checkTGSLayer(layer LayerInfo, path string, violation func(code, layer, format string, args ...interface{})) {
+	name := layer.Name
+	if path != "" {
+		name = path + " > " + name
+	}
+	if layer.Type == LayerImage {
+		violation("tgs-image", name, "image layers are not allowed")
+	}
+	if layer.Expressions > 0 {
+		violation("tgs-expression", name, "expressions are not allowed, found %d", layer.Expressions)
+	}
+	for _, child := range layer.Children {
+		checkTGSLayer(child, name, violation)
+	}
+}
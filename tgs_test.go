@@ -0,0 +1,93 @@
+package golottie
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ysmood/gson"
+)
+
+var stickerAnimData = []byte(`{"v":"5.5.2","fr":60,"ip":0,"op":180,"w":512,"h":512,"layers":[{"ind":1,"ty":4,"nm":"shape","ip":0,"op":180,"st":0,"ks":{},"shapes":[]}]}`)
+
+func Test_TGS(t *testing.T) {
+	sticker, err := EncodeTGS(stickerAnimData)
+	assert.NoError(t, err)
+	assert.True(t, IsTGS(sticker))
+	assert.False(t, IsTGS(stickerAnimData))
+
+	decoded, err := DecodeTGS(sticker)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, gson.New(decoded).Get("tgs").Int())
+
+	animation := NewAnimation(sticker)
+	assert.Equal(t, 180, animation.GetFramesTotal())
+	assert.Equal(t, 512, animation.GetWidth())
+	_, err = animation.WithDefaultTemplate()
+	assert.NoError(t, err)
+
+	_, err = DecodeTGS([]byte{0x1f, 0x8b, 0})
+	assert.Error(t, err)
+	// truncated stickers aren't parsed as JSON
+	_, err = NewAnimation(sticker[:20]).WithDefaultTemplate()
+	assert.ErrorContains(t, err, "error decoding tgs")
+	truncated := NewAnimation(sticker[:20])
+	_, err = truncated.Info()
+	assert.ErrorContains(t, err, "error decoding tgs")
+	_, err = truncated.Validate()
+	assert.ErrorContains(t, err, "error decoding tgs")
+	_, err = truncated.Analyze()
+	assert.ErrorContains(t, err, "error decoding tgs")
+	_, _, err = truncated.Optimize(DefaultOptimizeOptions)
+	assert.ErrorContains(t, err, "error decoding tgs")
+	_, err = EncodeTGS([]byte("(¬_¬)"))
+	assert.Error(t, err)
+}
+
+func Test_CheckTGS(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  func() []byte
+		codes []string
+	}{
+		{
+			name: "OK_sticker",
+			data: func() []byte {
+				sticker, _ := EncodeTGS(stickerAnimData)
+				return sticker
+			},
+		},
+		{
+			name:  "Plain_JSON",
+			data:  func() []byte { return stickerAnimData },
+			codes: []string{"tgs-flag"},
+		},
+		{
+			name:  "Bad_sticker",
+			data:  func() []byte { return exprAnimData },
+			codes: []string{"tgs-size", "tgs-image", "tgs-image", "tgs-expression", "tgs-flag"},
+		},
+		{
+			name: "Long_sticker",
+			data: func() []byte {
+				return []byte(strings.Replace(string(stickerAnimData), `"fr":60,"ip":0,"op":180`, `"fr":120,"ip":0,"op":480`, 1))
+			},
+			codes: []string{"tgs-frame-rate", "tgs-duration", "tgs-flag"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := CheckTGS(tt.data())
+			assert.NoError(t, err)
+			var codes []string
+			for _, issue := range report.Issues {
+				codes = append(codes, issue.Code)
+			}
+			assert.ElementsMatch(t, tt.codes, codes)
+		})
+	}
+	t.Run("Bad_data", func(t *testing.T) {
+		_, err := CheckTGS([]byte{0x1f, 0x8b})
+		assert.Error(t, err)
+	})
+}
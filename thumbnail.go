@@ -0,0 +1,165 @@
+package golottie
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// PosterStrategy selects how the poster frame is picked.
+type PosterStrategy int
+
+const (
+	// PosterFrame picks [PosterOptions.Frame], use [MarkerFrame]
+	// to pick the frame of a named marker.
+	PosterFrame PosterStrategy = iota
+	// PosterCoverage picks the frame with the most visible pixels.
+	PosterCoverage
+	// PosterAverage picks the frame most similar to the average frame.
+	PosterAverage
+)
+
+// posterSampleWidth is the width frames are scaled to before comparing.
+const posterSampleWidth = 64
+
+// PosterOptions configures [Renderer.Poster].
+type PosterOptions struct {
+	Strategy PosterStrategy
+	Frame    int
+	// Samples is the number of evenly spaced frames compared by
+	// PosterCoverage and PosterAverage strategies.
+	Samples int
+}
+
+// DefaultPosterOptions picks the frame with the most visible pixels
+// out of 30 sampled frames.
+var DefaultPosterOptions = PosterOptions{Strategy: PosterCoverage, Samples: 30}
+
+// MarkerFrame returns the frame the named marker starts at.
+// Returns [ErrFrameOutOfRange] if the marker lies outside the animation.
+func MarkerFrame(data []byte, name string) (int, error) {
+	c, err := ParseComposition(data)
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range c.Markers {
+		if m.Comment != name {
+			continue
+		}
+		frame := int(math.Round(m.Time - c.InPoint))
+		if frame < 0 || float64(frame) >= c.OutPoint-c.InPoint {
+			return 0, fmt.Errorf("error finding marker %q frame %d: %w", name, frame, ErrFrameOutOfRange)
+		}
+		return frame, nil
+	}
+	return 0, fmt.Errorf("error finding marker %q: %w", name, ErrMarkerNotFound)
+}
+
+// Poster renders a representative frame of the current animation.
+// The renderer continues from the frame after the last rendered one.
+//
+// Example:
+//
+//	frame, err := renderer.Poster(golottie.DefaultPosterOptions)
+//	...
+//	thumbs, err := golottie.Thumbnails(frame, []int{1200, 600})
+func (r *Renderer) Poster(opts PosterOptions) (Frame, error) {
+	if opts.Strategy == PosterFrame {
+		frames, err := r.RenderFrames([]int{opts.Frame})
+		if err != nil {
+			return Frame{}, err
+		}
+		return frames[0], nil
+	}
+	if opts.Samples <= 0 {
+		opts.Samples = DefaultPosterOptions.Samples
+	}
+	frames, err := r.RenderFrames(SampleFrames(r.framesTotal, opts.Samples))
+	if err != nil {
+		return Frame{}, err
+	}
+	if opts.Strategy == PosterAverage {
+		return ClosestToAverage(frames)
+	}
+	return MostCoverage(frames)
+}
+
+// MostCoverage returns PNG encoded frame with the most visible pixels
+// weighted by their opacity, the earliest one wins ties.
+func MostCoverage(frames []Frame) (Frame, error) {
+	best, bestCoverage := -1, -1
+	for i, f := range frames {
+		img, err := decodeNRGBA(f.Data)
+		if err != nil {
+			return Frame{}, fmt.Errorf("error decoding frame %d: %w", f.Num, err)
+		}
+		coverage := 0
+		for p := 3; p < len(img.Pix); p += 4 {
+			coverage += int(img.Pix[p])
+		}
+		if coverage > bestCoverage {
+			best, bestCoverage = i, coverage
+		}
+	}
+	if best < 0 {
+		return Frame{}, ErrNoFrames
+	}
+	return frames[best], nil
+}
+
+// ClosestToAverage returns PNG encoded frame with the smallest squared
+// difference from the average of all frames.
+func ClosestToAverage(frames []Frame) (Frame, error) {
+	if len(frames) == 0 {
+		return Frame{}, ErrNoFrames
+	}
+	samples := make([]*image.NRGBA, len(frames))
+	var sum []float64
+	for i, f := range frames {
+		img, err := decodeNRGBA(f.Data)
+		if err != nil {
+			return Frame{}, fmt.Errorf("error decoding frame %d: %w", f.Num, err)
+		}
+		w, h := fitSize(img.Rect.Dx(), img.Rect.Dy(), minInt(posterSampleWidth, img.Rect.Dx()))
+		samples[i] = resizeImage(img, w, h)
+		if sum == nil {
+			sum = make([]float64, len(samples[i].Pix))
+		}
+		if len(samples[i].Pix) != len(sum) {
+			return Frame{}, fmt.Errorf("error comparing frame %d: dimensions differ", f.Num)
+		}
+		for p, v := range samples[i].Pix {
+			sum[p] += float64(v)
+		}
+	}
+	best, bestDiff := 0, math.Inf(1)
+	for i, s := range samples {
+		diff := 0.0
+		for p, v := range s.Pix {
+			d := float64(v) - sum[p]/float64(len(samples))
+			diff += d * d
+		}
+		if diff < bestDiff {
+			best, bestDiff = i, diff
+		}
+	}
+	return frames[best], nil
+}
+
+// Thumbnails scales PNG encoded frame to every width keeping the aspect ratio.
+func Thumbnails(f Frame, widths []int) ([]*image.NRGBA, error) {
+	img, err := decodeNRGBA(f.Data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding frame %d: %w", f.Num, err)
+	}
+	thumbs := make([]*image.NRGBA, len(widths))
+	for i, width := range widths {
+		if width <= 0 || width == img.Rect.Dx() {
+			thumbs[i] = img
+			continue
+		}
+		w, h := fitSize(img.Rect.Dx(), img.Rect.Dy(), width)
+		thumbs[i] = resizeImage(img, w, h)
+	}
+	return thumbs, nil
+}
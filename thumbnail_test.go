@@ -0,0 +1,67 @@
+package golottie
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// coverageFrame encodes transparent 8x4 frame with left n columns filled.
+func coverageFrame(t *testing.T, n int, c color.NRGBA) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 4))
+	draw.Draw(img, image.Rect(0, 0, n, 4), image.NewUniform(c), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func Test_MarkerFrame(t *testing.T) {
+	frame, err := MarkerFrame(markerAnimData, "intro")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, frame)
+	frame, err = MarkerFrame(markerAnimData, "also loop")
+	assert.NoError(t, err)
+	assert.Equal(t, 30, frame)
+	_, err = MarkerFrame(markerAnimData, "outro")
+	assert.ErrorIs(t, err, ErrMarkerNotFound)
+	_, err = MarkerFrame(markerAnimData, "after out point")
+	assert.ErrorIs(t, err, ErrFrameOutOfRange)
+	assert.Contains(t, err.Error(), "after out point")
+}
+
+func Test_PosterSelection(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	frames := []Frame{
+		{Num: 0, Data: coverageFrame(t, 0, red)},
+		{Num: 10, Data: coverageFrame(t, 4, red)},
+		{Num: 20, Data: coverageFrame(t, 8, red)},
+		{Num: 30, Data: coverageFrame(t, 5, red)},
+	}
+	f, err := MostCoverage(frames)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, f.Num)
+
+	f, err = ClosestToAverage(frames)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, f.Num)
+
+	_, err = MostCoverage(nil)
+	assert.ErrorIs(t, err, ErrNoFrames)
+	_, err = ClosestToAverage(nil)
+	assert.ErrorIs(t, err, ErrNoFrames)
+}
+
+func Test_Thumbnails(t *testing.T) {
+	thumbs, err := Thumbnails(Frame{Data: coverageFrame(t, 4, color.NRGBA{B: 255, A: 255})}, []int{8, 4, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, image.Pt(8, 4), thumbs[0].Rect.Size())
+	assert.Equal(t, image.Pt(4, 2), thumbs[1].Rect.Size())
+	assert.Equal(t, image.Pt(2, 1), thumbs[2].Rect.Size())
+	assert.Equal(t, color.NRGBA{B: 255, A: 255}, thumbs[2].NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{}, thumbs[2].NRGBAAt(1, 0))
+}
@@ -0,0 +1,460 @@
+package golottie
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Severity is a severity of a validation issue.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = [...]string{"info", "warning", "error"}
+
+// String returns severity name.
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity parses a severity name.
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(n, name) {
+			return Severity(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q", name)
+}
+
+// Player names used by compatibility issues.
+const (
+	PlayerSVG     = "svg"
+	PlayerCanvas  = "canvas"
+	PlayerIOS     = "ios"
+	PlayerAndroid = "android"
+)
+
+// Players contains all players the compatibility is checked against.
+var Players = []string{PlayerSVG, PlayerCanvas, PlayerIOS, PlayerAndroid}
+
+// Issue is a single problem found in the animation.
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	// Layer is a path to the layer the issue was found in, nested precomp
+	// layers are separated by " > ". Empty for composition issues.
+	Layer   string `json:"layer,omitempty"`
+	Message string `json:"message"`
+	// Players contains players which don't support the feature.
+	// Empty for schema issues.
+	Players []string `json:"players,omitempty"`
+}
+
+// Report is a list of issues found in the animation.
+type Report struct {
+	Issues []Issue `json:"issues"`
+}
+
+// Max returns the highest severity in the report.
+// Returns -1 if the report has no issues.
+func (r *Report) Max() Severity {
+	max := Severity(-1)
+	for _, issue := range r.Issues {
+		if issue.Severity > max {
+			max = issue.Severity
+		}
+	}
+	return max
+}
+
+// Count returns the number of issues with the provided severity.
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Filter returns a report with the schema issues and the compatibility issues
+// affecting at least one of the provided players.
+func (r *Report) Filter(players ...string) *Report {
+	filtered := &Report{}
+	for _, issue := range r.Issues {
+		if len(issue.Players) == 0 {
+			filtered.Issues = append(filtered.Issues, issue)
+			continue
+		}
+		var affected []string
+		for _, p := range issue.Players {
+			for _, player := range players {
+				if p == player {
+					affected = append(affected, p)
+				}
+			}
+		}
+		if len(affected) > 0 {
+			issue.Players = affected
+			filtered.Issues = append(filtered.Issues, issue)
+		}
+	}
+	return filtered
+}
+
+// WriteText writes human-readable report to w, most severe issues first.
+func (r *Report) WriteText(w io.Writer) error {
+	issues := append([]Issue(nil), r.Issues...)
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Severity > issues[j].Severity
+	})
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, issue := range issues {
+		layer := issue.Layer
+		if layer == "" {
+			layer = "-"
+		}
+		message := issue.Message
+		if len(issue.Players) > 0 {
+			message += " (" + strings.Join(issue.Players, ", ") + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", issue.Severity, layer, issue.Code, message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d errors, %d warnings, %d info\n",
+		r.Count(SeverityError), r.Count(SeverityWarning), r.Count(SeverityInfo))
+	return err
+}
+
+// compatRule describes a feature poorly supported by some players.
+type compatRule struct {
+	severity Severity
+	message  string
+	players  []string
+}
+
+var compatRules = map[string]compatRule{
+	"expression": {SeverityWarning, "expressions are not supported", []string{PlayerIOS, PlayerAndroid}},
+	"3d-layer":   {SeverityWarning, "3D layers are only supported by the html renderer", []string{PlayerSVG, PlayerCanvas, PlayerIOS, PlayerAndroid}},
+	"camera":     {SeverityWarning, "camera layers are ignored", []string{PlayerSVG, PlayerCanvas, PlayerIOS, PlayerAndroid}},
+	"luma-matte": {SeverityWarning, "luma mattes are poorly supported", []string{PlayerCanvas, PlayerIOS}},
+	"blend-mode": {SeverityWarning, "blend modes are not supported", []string{PlayerCanvas, PlayerIOS, PlayerAndroid}},
+	"merge-path": {SeverityWarning, "merge paths are ignored or need to be enabled explicitly", []string{PlayerSVG, PlayerCanvas, PlayerIOS, PlayerAndroid}},
+	"effect":     {SeverityWarning, "layer effects are not supported", []string{PlayerCanvas, PlayerIOS, PlayerAndroid}},
+	"effect-svg": {SeverityWarning, "layer effect is not supported", []string{PlayerSVG}},
+	"pucker":     {SeverityWarning, "pucker and bloat is not supported", []string{PlayerIOS, PlayerAndroid}},
+	"twist":      {SeverityWarning, "twist is not supported", []string{PlayerSVG, PlayerCanvas, PlayerIOS, PlayerAndroid}},
+	"zig-zag":    {SeverityWarning, "zig zag is not supported", []string{PlayerIOS, PlayerAndroid}},
+	"offset":     {SeverityWarning, "offset path is not supported", []string{PlayerIOS, PlayerAndroid}},
+	"text-glyph": {SeverityInfo, "text layer relies on system fonts, rendering may differ", []string{PlayerSVG, PlayerCanvas, PlayerIOS, PlayerAndroid}},
+	"media":      {SeverityInfo, "audio, video and data layers are ignored", []string{PlayerSVG, PlayerCanvas, PlayerIOS, PlayerAndroid}},
+}
+
+// effectControls is a type of expression controls effect group
+// which isn't rendered by any player.
+const effectControls = 5
+
+// svgEffects contains effect types supported by lottie-web SVG renderer.
+var svgEffects = map[int]bool{20: true, 21: true, 22: true, 23: true, 24: true, 25: true, 28: true, 29: true}
+
+// Validate checks animation data against the Lottie format and reports features
+// poorly supported by lottie-web, lottie-ios or lottie-android. An error is returned
+// only if data isn't valid JSON.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("animation.json")
+//	report, err := golottie.Validate(data)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if report.Max() >= golottie.SeverityError {
+//		report.WriteText(os.Stderr)
+//	}
+func Validate(data []byte) (*Report, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("error validating animation: %w", ErrNilAnimationData)
+	}
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("error validating animation: %w", err)
+	}
+	v := &validator{report: &Report{}}
+	v.validate(root)
+	return v.report, nil
+}
+
+// Validate validates the initial animation data, see [Validate].
+func (a *AnimationData) Validate() (*Report, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return Validate(a.data)
+}
+
+type validator struct {
+	report  *Report
+	assets  map[string]map[string]interface{}
+	hasText bool
+}
+
+func (v *validator) schema(severity Severity, layer, code, format string, args ...interface{}) {
+	v.report.Issues = append(v.report.Issues, Issue{
+		Severity: severity,
+		Code:     code,
+		Layer:    layer,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) compat(layer, code, detail string) {
+	rule := compatRules[code]
+	message := rule.message
+	if detail != "" {
+		message += ": " + detail
+	}
+	v.report.Issues = append(v.report.Issues, Issue{
+		Severity: rule.severity,
+		Code:     code,
+		Layer:    layer,
+		Message:  message,
+		Players:  rule.players,
+	})
+}
+
+func (v *validator) validate(root interface{}) {
+	comp, ok := root.(map[string]interface{})
+	if !ok {
+		v.schema(SeverityError, "", "schema", "animation must be a JSON object")
+		return
+	}
+	if _, ok := comp["v"].(string); !ok {
+		v.schema(SeverityWarning, "", "schema", `missing Bodymovin version "v"`)
+	}
+	if fr, ok := comp["fr"].(float64); !ok || fr <= 0 {
+		v.schema(SeverityError, "", "schema", `frame rate "fr" must be a positive number`)
+	}
+	for _, key := range []string{"w", "h"} {
+		if n, ok := comp[key].(float64); !ok || n <= 0 {
+			v.schema(SeverityError, "", "schema", "%q must be a positive number", key)
+		}
+	}
+	ip, ipOK := comp["ip"].(float64)
+	op, opOK := comp["op"].(float64)
+	if !ipOK || !opOK {
+		v.schema(SeverityError, "", "schema", `in and out points "ip" and "op" must be numbers`)
+	} else if op <= ip {
+		v.schema(SeverityError, "", "schema", "out point %g must be greater than in point %g", op, ip)
+	}
+	if ddd, _ := comp["ddd"].(float64); ddd != 0 {
+		v.compat("", "3d-layer", "composition is 3D")
+	}
+
+	v.assets = make(map[string]map[string]interface{})
+	assets, ok := comp["assets"].([]interface{})
+	if _, has := comp["assets"]; has && !ok {
+		v.schema(SeverityError, "", "schema", `"assets" must be an array`)
+	}
+	for i, a := range assets {
+		asset, ok := a.(map[string]interface{})
+		if !ok {
+			v.schema(SeverityError, "", "schema", "asset %d must be an object", i)
+			continue
+		}
+		id, ok := asset["id"].(string)
+		if !ok || id == "" {
+			v.schema(SeverityError, "", "schema", "asset %d has no id", i)
+			continue
+		}
+		if _, dup := v.assets[id]; dup {
+			v.schema(SeverityError, "", "schema", "duplicate asset id %q", id)
+		}
+		v.assets[id] = asset
+	}
+
+	layers, ok := comp["layers"].([]interface{})
+	if !ok {
+		v.schema(SeverityError, "", "schema", `"layers" must be an array`)
+		return
+	}
+	v.layers(layers, "", map[string]bool{})
+	if _, hasChars := comp["chars"]; v.hasText && !hasChars {
+		v.compat("", "text-glyph", "")
+	}
+}
+
+// layers validates layers of a composition, descending into precomps.
+// Path contains the path to the precomp layer the layers belong to.
+// Each precomp is validated once, under the first layer that references it.
+func (v *validator) layers(layers []interface{}, path string, visited map[string]bool) {
+	indices := make(map[float64]bool)
+	for _, l := range layers {
+		if layer, ok := l.(map[string]interface{}); ok {
+			if ind, ok := layer["ind"].(float64); ok {
+				indices[ind] = true
+			}
+		}
+	}
+	for i, l := range layers {
+		layer, ok := l.(map[string]interface{})
+		if !ok {
+			v.schema(SeverityError, path, "schema", "layer %d must be an object", i)
+			continue
+		}
+		name := fmt.Sprintf("#%d", i)
+		if nm, ok := layer["nm"].(string); ok && nm != "" {
+			name = nm
+		}
+		if path != "" {
+			name = path + " > " + name
+		}
+		v.layer(layer, name, indices)
+
+		ty, _ := layer["ty"].(float64)
+		refID, _ := layer["refId"].(string)
+		if LayerType(ty) != LayerPrecomp || visited[refID] {
+			continue
+		}
+		if precomp, ok := v.assets[refID]; ok {
+			children, _ := precomp["layers"].([]interface{})
+			visited[refID] = true
+			v.layers(children, name, visited)
+		}
+	}
+}
+
+//gocyclo:ignore
+func (v *validator) layer(layer map[string]interface{}, name string, indices map[float64]bool) {
+	ty, ok := layer["ty"].(float64)
+	if !ok {
+		v.schema(SeverityError, name, "schema", `layer type "ty" must be a number`)
+		return
+	}
+	layerType := LayerType(ty)
+	if layerType < 0 || int(layerType) >= len(layerTypeNames) {
+		v.schema(SeverityError, name, "schema", "unknown layer type %d", int(ty))
+		return
+	}
+	ip, ipOK := layer["ip"].(float64)
+	op, opOK := layer["op"].(float64)
+	if !ipOK || !opOK {
+		v.schema(SeverityError, name, "schema", `in and out points "ip" and "op" must be numbers`)
+	} else if op <= ip {
+		v.schema(SeverityWarning, name, "schema", "layer is never visible, out point %g isn't greater than in point %g", op, ip)
+	}
+	if _, ok := layer["ks"].(map[string]interface{}); !ok && layerType != LayerAudio && layerType != LayerData {
+		v.schema(SeverityError, name, "schema", `missing transform "ks"`)
+	}
+	if parent, ok := layer["parent"].(float64); ok && !indices[parent] {
+		v.schema(SeverityError, name, "schema", "parent layer %g doesn't exist", parent)
+	}
+
+	switch layerType {
+	case LayerPrecomp, LayerImage:
+		refID, _ := layer["refId"].(string)
+		asset, ok := v.assets[refID]
+		if !ok {
+			v.schema(SeverityError, name, "schema", "referenced asset %q doesn't exist", refID)
+			break
+		}
+		_, isPrecomp := asset["layers"]
+		if layerType == LayerPrecomp && !isPrecomp {
+			v.schema(SeverityError, name, "schema", "referenced asset %q isn't a precomp", refID)
+		}
+		if layerType == LayerImage && isPrecomp {
+			v.schema(SeverityError, name, "schema", "referenced asset %q isn't an image", refID)
+		}
+		if layerType == LayerImage {
+			p, _ := asset["p"].(string)
+			e, _ := asset["e"].(float64)
+			if e == 0 && !strings.HasPrefix(p, "data:") {
+				v.schema(SeverityWarning, name, "external-image", "image %q isn't embedded and may fail to load", p)
+			}
+		}
+	case LayerShape:
+		shapes, ok := layer["shapes"].([]interface{})
+		if !ok {
+			v.schema(SeverityError, name, "schema", `shape layer has no "shapes" array`)
+			break
+		}
+		v.shapes(shapes, name)
+	case LayerText:
+		v.hasText = true
+	case LayerCamera:
+		v.compat(name, "camera", "")
+	case LayerAudio, LayerVideo, LayerData:
+		v.compat(name, "media", "")
+	}
+
+	if ddd, _ := layer["ddd"].(float64); ddd != 0 {
+		v.compat(name, "3d-layer", "")
+	}
+	if tt, _ := layer["tt"].(float64); tt == 3 || tt == 4 {
+		v.compat(name, "luma-matte", "")
+	}
+	if bm, _ := layer["bm"].(float64); bm != 0 {
+		v.compat(name, "blend-mode", fmt.Sprintf("mode %g", bm))
+	}
+	effects, _ := layer["ef"].([]interface{})
+	rendered := 0
+	for _, e := range effects {
+		effect, _ := e.(map[string]interface{})
+		ty, _ := effect["ty"].(float64)
+		if ty == effectControls {
+			continue
+		}
+		rendered++
+		if !svgEffects[int(ty)] {
+			nm, _ := effect["nm"].(string)
+			v.compat(name, "effect-svg", fmt.Sprintf("%q (type %g)", nm, ty))
+		}
+	}
+	if rendered > 0 {
+		v.compat(name, "effect", fmt.Sprintf("%d effects", rendered))
+	}
+	if n := countExpressionsIn(layer); n > 0 {
+		v.compat(name, "expression", fmt.Sprintf("%d properties", n))
+	}
+}
+
+var shapeRules = map[string]string{
+	"mm": "merge-path",
+	"pb": "pucker",
+	"tw": "twist",
+	"zz": "zig-zag",
+	"op": "offset",
+}
+
+func (v *validator) shapes(shapes []interface{}, name string) {
+	found := make(map[string]bool)
+	walkJSON(shapes, func(shape map[string]interface{}) {
+		ty, ok := shape["ty"].(string)
+		if !ok {
+			return
+		}
+		if code, ok := shapeRules[ty]; ok && !found[code] {
+			found[code] = true
+			v.compat(name, code, "")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package golottie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exprValidateData uses an expression and an external image, the shape
+// layer is well-formed so the warnings are the only issues.
+var exprValidateData = []byte(`{"v":"5.7.4","nm":"expr","fr":60,"ip":0,"op":120,"w":512,"h":256,
+"assets":[{"id":"image_0","w":64,"h":64,"u":"images/","p":"img_0.png","e":0}],
+"layers":[
+{"ind":1,"ty":3,"nm":"Null","ip":0,"op":120,"st":0,"ks":{"r":{"a":0,"k":0,"x":"wiggle(1, 10)"}}},
+{"ind":2,"ty":4,"nm":"Shape","parent":1,"ip":10,"op":60,"st":0,"ks":{},"shapes":[]},
+{"ind":3,"ty":2,"nm":"Image","refId":"image_0","ip":0,"op":120,"st":0,"ks":{}}
+]}`)
+
+func Test_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		max   Severity
+		codes []string
+	}{
+		{
+			name: "OK_animation",
+			data: animData,
+			max:  -1,
+		},
+		{
+			name:  "Expressions_animation",
+			data:  exprValidateData,
+			max:   SeverityWarning,
+			codes: []string{"expression", "external-image"},
+		},
+		{
+			name:  "Broken_animation",
+			data:  []byte(`{"fr":0,"w":10,"h":10,"ip":10,"op":5,"layers":[{"ty":0,"refId":"nope","ip":0,"op":1,"ks":{},"parent":3},{"ty":42}]}`),
+			max:   SeverityError,
+			codes: []string{"schema"},
+		},
+		{
+			name:  "Unsupported_features",
+			data:  []byte(`{"v":"5.7.0","fr":30,"w":10,"h":10,"ip":0,"op":5,"layers":[{"ty":4,"nm":"shape","ddd":1,"bm":3,"tt":3,"ip":0,"op":5,"ks":{},"ef":[{"ty":5},{"ty":27,"nm":"Displace"}],"shapes":[{"ty":"gr","it":[{"ty":"mm"},{"ty":"pb"}]}]},{"ty":13,"ip":0,"op":5,"ks":{}}]}`),
+			max:   SeverityWarning,
+			codes: []string{"3d-layer", "blend-mode", "luma-matte", "effect", "effect-svg", "merge-path", "pucker", "camera"},
+		},
+		{
+			name:  "Not_an_object",
+			data:  []byte(`[]`),
+			max:   SeverityError,
+			codes: []string{"schema"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Validate(tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.max, report.Max(), "%+v", report.Issues)
+			codes := make(map[string]bool)
+			for _, issue := range report.Issues {
+				codes[issue.Code] = true
+			}
+			for _, code := range tt.codes {
+				assert.True(t, codes[code], "missing %q issue in %+v", code, report.Issues)
+			}
+			var buf bytes.Buffer
+			assert.NoError(t, report.WriteText(&buf))
+		})
+	}
+	t.Run("Precomp_path", func(t *testing.T) {
+		data := []byte(`{"v":"5.7.0","fr":30,"w":10,"h":10,"ip":0,"op":5,"assets":[{"id":"comp","layers":[{"ty":4,"nm":"inner","ip":0,"op":5,"ks":{},"shapes":[{"ty":"mm"}]}]}],"layers":[{"ty":0,"nm":"outer","refId":"comp","ip":0,"op":5,"ks":{}}]}`)
+		report, err := Validate(data)
+		assert.NoError(t, err)
+		assert.Len(t, report.Issues, 1)
+		assert.Equal(t, "outer > inner", report.Issues[0].Layer)
+	})
+	t.Run("Shared_precomp", func(t *testing.T) {
+		data := []byte(`{"v":"5.7.0","fr":30,"w":10,"h":10,"ip":0,"op":5,"assets":[{"id":"comp","layers":[{"ty":4,"nm":"inner","ip":0,"op":5,"ks":{},"shapes":[{"ty":"mm"}]}]}],"layers":[{"ty":0,"nm":"a","refId":"comp","ip":0,"op":5,"ks":{}},{"ty":0,"nm":"b","refId":"comp","ip":0,"op":5,"ks":{}}]}`)
+		report, err := Validate(data)
+		assert.NoError(t, err)
+		assert.Len(t, report.Issues, 1)
+		assert.Equal(t, "a > inner", report.Issues[0].Layer)
+	})
+	t.Run("Filter_players", func(t *testing.T) {
+		report, err := NewAnimation(exprValidateData).Validate()
+		assert.NoError(t, err)
+		filtered := report.Filter(PlayerSVG)
+		for _, issue := range filtered.Issues {
+			assert.NotEqual(t, "expression", issue.Code)
+		}
+		assert.Less(t, len(filtered.Issues), len(report.Issues))
+	})
+	t.Run("Invalid_JSON", func(t *testing.T) {
+		_, err := Validate([]byte("(ಥ﹏ಥ)"))
+		assert.Error(t, err)
+		_, err = Validate(nil)
+		assert.ErrorIs(t, err, ErrNilAnimationData)
+	})
+}
+
+func Test_ParseSeverity(t *testing.T) {
+	s, err := ParseSeverity("Warning")
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityWarning, s)
+	_, err = ParseSeverity("meh")
+	assert.Error(t, err)
+}
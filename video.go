@@ -0,0 +1,143 @@
+package golottie
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// Y4MSink streams frames as YUV4MPEG2 video with 4:2:0 chroma
+// subsampling, so it can be piped into video encoders.
+// Frames are written as they come and must be written in order.
+//
+// Example:
+//
+//	sink := golottie.NewY4MSink(os.Stdout, color.Black)
+type Y4MSink struct {
+	rawSink
+}
+
+// RawSink streams frames as raw 8-bit RGBA pixels, row by row.
+// Frames are written as they come and must be written in order.
+type RawSink struct {
+	rawSink
+}
+
+type rawSink struct {
+	w          *bufio.Writer
+	background color.Color
+	meta       Metadata
+	img        *image.NRGBA
+	y4m        bool
+}
+
+// NewY4MSink returns Y4M sink writing to w, transparent pixels are
+// drawn over the background, black if nil.
+func NewY4MSink(w io.Writer, background color.Color) *Y4MSink {
+	if background == nil {
+		background = color.Black
+	}
+	return &Y4MSink{rawSink{w: bufio.NewWriter(w), background: background, y4m: true}}
+}
+
+// NewRawSink returns raw RGBA sink writing to w, transparent pixels are
+// drawn over the background. Nil background keeps the alpha channel.
+func NewRawSink(w io.Writer, background color.Color) *RawSink {
+	return &RawSink{rawSink{w: bufio.NewWriter(w), background: background}}
+}
+
+// Open writes stream header if any. Frame dimensions are taken from
+// the metadata and must be known in advance.
+func (s *rawSink) Open(meta Metadata) error {
+	if meta.Width <= 0 || meta.Height <= 0 {
+		return fmt.Errorf("error opening sink: invalid dimensions %dx%d", meta.Width, meta.Height)
+	}
+	s.meta = meta
+	s.img = image.NewNRGBA(image.Rect(0, 0, meta.Width, meta.Height))
+	if !s.y4m {
+		return nil
+	}
+	if meta.Width%2 != 0 || meta.Height%2 != 0 {
+		return fmt.Errorf("error opening sink: 4:2:0 video requires even dimensions, got %dx%d", meta.Width, meta.Height)
+	}
+	num, den := frameRateRatio(meta.FrameRate)
+	_, err := fmt.Fprintf(s.w, "YUV4MPEG2 W%d H%d F%d:%d Ip A1:1 C420jpeg XCOLORRANGE=FULL\n", meta.Width, meta.Height, num, den)
+	return err
+}
+
+// WriteFrame writes PNG encoded frame.
+func (s *rawSink) WriteFrame(f Frame) error {
+	src, err := png.Decode(bytes.NewReader(f.Data))
+	if err != nil {
+		return fmt.Errorf("error decoding frame %d: %w", f.Num, err)
+	}
+	b := s.img.Bounds()
+	if s.background != nil {
+		draw.Draw(s.img, b, image.NewUniform(s.background), image.Point{}, draw.Src)
+		draw.Draw(s.img, b, src, src.Bounds().Min, draw.Over)
+	} else {
+		draw.Draw(s.img, b, src, src.Bounds().Min, draw.Src)
+	}
+	if !s.y4m {
+		_, err = s.w.Write(s.img.Pix)
+		return err
+	}
+	if _, err = s.w.WriteString("FRAME\n"); err != nil {
+		return err
+	}
+	_, err = s.w.Write(yuv420(s.img))
+	return err
+}
+
+// Close flushes buffered output, the underlying writer is not closed.
+func (s *rawSink) Close() error {
+	return s.w.Flush()
+}
+
+// yuv420 converts opaque image into planar full range BT.601 Y'CbCr 4:2:0
+// averaging chroma over 2x2 blocks.
+func yuv420(img *image.NRGBA) []byte {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	out := make([]byte, w*h+2*(w/2)*(h/2))
+	cb, cr := out[w*h:w*h+(w/2)*(h/2)], out[w*h+(w/2)*(h/2):]
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := img.Pix[y*img.Stride+x*4:]
+			out[y*w+x], _, _ = color.RGBToYCbCr(p[0], p[1], p[2])
+		}
+	}
+	for y := 0; y < h/2; y++ {
+		for x := 0; x < w/2; x++ {
+			var r, g, b int
+			for _, o := range [4]int{0, 4, img.Stride, img.Stride + 4} {
+				p := img.Pix[2*y*img.Stride+2*x*4+o:]
+				r, g, b = r+int(p[0]), g+int(p[1]), b+int(p[2])
+			}
+			_, cb[y*(w/2)+x], cr[y*(w/2)+x] = color.RGBToYCbCr(uint8(r/4), uint8(g/4), uint8(b/4))
+		}
+	}
+	return out
+}
+
+// frameRateRatio returns frame rate as a reduced fraction.
+func frameRateRatio(frameRate float64) (int, int) {
+	if frameRate <= 0 {
+		return 30, 1
+	}
+	num, den := int(frameRate*1000+0.5), 1000
+	g := gcd(num, den)
+	return num / g, den / g
+}
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
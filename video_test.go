@@ -0,0 +1,55 @@
+package golottie
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Y4MSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewY4MSink(&buf, color.White)
+	assert.NoError(t, sink.Open(Metadata{Width: 4, Height: 4, FrameRate: 29.97}))
+	frame := pngFrame(t, color.NRGBA{A: 255})
+	assert.NoError(t, sink.WriteFrame(Frame{Num: 0, Data: frame}))
+	assert.NoError(t, sink.WriteFrame(Frame{Num: 1, Data: frame}))
+	assert.NoError(t, sink.Close())
+
+	header := "YUV4MPEG2 W4 H4 F2997:100 Ip A1:1 C420jpeg XCOLORRANGE=FULL\n"
+	frameSize := len("FRAME\n") + 16 + 4 + 4
+	data := buf.Bytes()
+	assert.Equal(t, header, string(data[:len(header)]))
+	assert.Len(t, data, len(header)+2*frameSize)
+	f := data[len(header):]
+	assert.Equal(t, "FRAME\n", string(f[:6]))
+	// transparent top-left pixel is drawn over white background
+	assert.Equal(t, byte(255), f[6])
+	assert.Equal(t, byte(0), f[7])
+
+	assert.Error(t, NewY4MSink(&buf, nil).Open(Metadata{Width: 3, Height: 4}))
+}
+
+func Test_RawSink(t *testing.T) {
+	tests := []struct {
+		name       string
+		background color.Color
+		topLeft    []byte
+	}{
+		{name: "keeps alpha", topLeft: []byte{0, 0, 0, 0}},
+		{name: "flattened", background: color.NRGBA{B: 255, A: 255}, topLeft: []byte{0, 0, 255, 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			sink := NewRawSink(&buf, tt.background)
+			assert.NoError(t, sink.Open(Metadata{Width: 4, Height: 4}))
+			assert.NoError(t, sink.WriteFrame(Frame{Data: pngFrame(t, color.NRGBA{R: 255, A: 255})}))
+			assert.NoError(t, sink.Close())
+			assert.Len(t, buf.Bytes(), 4*4*4)
+			assert.Equal(t, tt.topLeft, buf.Bytes()[:4])
+			assert.Equal(t, []byte{255, 0, 0, 255}, buf.Bytes()[4:8])
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package golottie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// WebP VP8X feature flags.
+const (
+	webpFlagAnimation = 0x02
+	webpFlagAlpha     = 0x10
+)
+
+// WebPOptions configures [WebPSink].
+type WebPOptions struct {
+	// Quality in range [1..100] frames are captured with, 100 is lossless.
+	// Zero captures lossless frames, greater values are clamped to 100.
+	Quality int
+	// LoopCount is the number of times the animation is repeated,
+	// 0 loops forever and -1 shows every frame once.
+	LoopCount int
+}
+
+// DefaultWebPOptions loops lossless animation forever.
+var DefaultWebPOptions = WebPOptions{Quality: 100}
+
+// WebPSink muxes frames captured as WebP into an animated WebP.
+// Frames are buffered and the animation is written to w on [WebPSink.Close]
+// since RIFF header contains the file size.
+//
+// Example:
+//
+//	sink := golottie.NewWebPSink(f, golottie.DefaultWebPOptions)
+//	sink.Open(animation.Metadata())
+//	format, quality := sink.FrameFormat()
+//	for renderer.NextFrame() {
+//		renderer.RenderFrameAs(&buf, format, quality)
+//		sink.WriteFrame(golottie.Frame{Num: n, Data: buf})
+//	}
+//	err := sink.Close()
+type WebPSink struct {
+	w      io.Writer
+	opts   WebPOptions
+	meta   Metadata
+	frames []webpFrame
+}
+
+type webpFrame struct {
+	num           int
+	width, height int
+	alpha         bool
+	// data contains ALPH, VP8 and VP8L chunks of the frame
+	data []byte
+}
+
+// NewWebPSink returns WebP sink writing to w.
+func NewWebPSink(w io.Writer, opts WebPOptions) *WebPSink {
+	if opts.Quality <= 0 || opts.Quality > 100 {
+		// unset and lossless alike
+		opts.Quality = 100
+	}
+	return &WebPSink{w: w, opts: opts}
+}
+
+// FrameFormat implements [FrameFormatter].
+func (s *WebPSink) FrameFormat() (ImageFormat, int) {
+	return FormatWebP, s.opts.Quality
+}
+
+// Open prepares the sink for the animation.
+func (s *WebPSink) Open(meta Metadata) error {
+	s.meta = meta
+	s.frames = s.frames[:0]
+	return nil
+}
+
+// WriteFrame buffers WebP encoded frame.
+func (s *WebPSink) WriteFrame(f Frame) error {
+	frame, err := parseWebP(f.Data)
+	if err != nil {
+		return fmt.Errorf("error reading frame %d: %w", f.Num, err)
+	}
+	frame.num = f.Num
+	s.frames = append(s.frames, frame)
+	return nil
+}
+
+// Close writes the animated WebP.
+func (s *WebPSink) Close() error {
+	if len(s.frames) == 0 {
+		return ErrNoFrames
+	}
+	sort.SliceStable(s.frames, func(i, j int) bool { return s.frames[i].num < s.frames[j].num })
+	width, height := s.meta.Width, s.meta.Height
+	flags := byte(webpFlagAnimation)
+	for _, f := range s.frames {
+		if f.width > width {
+			width = f.width
+		}
+		if f.height > height {
+			height = f.height
+		}
+		if f.alpha {
+			flags |= webpFlagAlpha
+		}
+	}
+
+	// frames are shown from the time of their number until the next one,
+	// so the frame before missing ones is held
+	at := func(i int) int {
+		if i == len(s.frames) {
+			return s.meta.frameTime(s.frames[i-1].num+1, 1000)
+		}
+		return s.meta.frameTime(s.frames[i].num, 1000)
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	vp8x := append([]byte{flags, 0, 0, 0}, u24(width-1)...)
+	writeRIFFChunk(&body, "VP8X", append(vp8x, u24(height-1)...))
+	loops := 0
+	if s.opts.LoopCount < 0 {
+		loops = 1
+	} else if s.opts.LoopCount > 0 {
+		loops = s.opts.LoopCount + 1
+	}
+	// transparent background, loop count
+	writeRIFFChunk(&body, "ANIM", []byte{0, 0, 0, 0, byte(loops), byte(loops >> 8)})
+	for i, f := range s.frames {
+		var anmf []byte
+		anmf = append(anmf, u24(0)...) // X / 2
+		anmf = append(anmf, u24(0)...) // Y / 2
+		anmf = append(anmf, u24(f.width-1)...)
+		anmf = append(anmf, u24(f.height-1)...)
+		anmf = append(anmf, u24(at(i+1)-at(i))...)
+		// do not blend, do not dispose
+		anmf = append(anmf, 0x02)
+		writeRIFFChunk(&body, "ANMF", append(anmf, f.data...))
+	}
+	s.frames = nil
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(body.Len())))
+	if _, err := body.WriteTo(&buf); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(s.w)
+	return err
+}
+
+// parseWebP returns image chunks and dimensions of a still WebP image.
+func parseWebP(data []byte) (webpFrame, error) {
+	var f webpFrame
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return f, ErrInvalidWebP
+	}
+	for rest := data[12:]; len(rest) >= 8; {
+		name := string(rest[:4])
+		size := int(binary.LittleEndian.Uint32(rest[4:8]))
+		if 8+size > len(rest) {
+			return f, fmt.Errorf("%w: truncated %s chunk", ErrInvalidWebP, name)
+		}
+		end := 8 + size + size&1
+		if end > len(rest) {
+			end = len(rest)
+		}
+		payload := rest[8 : 8+size]
+		switch name {
+		case "VP8 ":
+			// frame tag, start code, 14 bit dimensions
+			if len(payload) < 10 || !bytes.Equal(payload[3:6], []byte{0x9d, 0x01, 0x2a}) {
+				return f, fmt.Errorf("%w: bad VP8 header", ErrInvalidWebP)
+			}
+			f.width = int(binary.LittleEndian.Uint16(payload[6:]) & 0x3fff)
+			f.height = int(binary.LittleEndian.Uint16(payload[8:]) & 0x3fff)
+		case "VP8L":
+			if len(payload) < 5 || payload[0] != 0x2f {
+				return f, fmt.Errorf("%w: bad VP8L header", ErrInvalidWebP)
+			}
+			bits := binary.LittleEndian.Uint32(payload[1:])
+			f.width = int(bits&0x3fff) + 1
+			f.height = int(bits>>14&0x3fff) + 1
+			f.alpha = bits>>28&1 == 1
+		case "ALPH":
+			f.alpha = true
+		default:
+			rest = rest[end:]
+			continue
+		}
+		f.data = append(f.data, rest[:end]...)
+		rest = rest[end:]
+	}
+	if f.width == 0 || f.height == 0 {
+		return f, fmt.Errorf("%w: no image data", ErrInvalidWebP)
+	}
+	return f, nil
+}
+
+func writeRIFFChunk(w *bytes.Buffer, name string, data []byte) {
+	w.WriteString(name)
+	w.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(data))))
+	w.Write(data)
+	if len(data)&1 == 1 {
+		w.WriteByte(0)
+	}
+}
+
+func u24(v int) []byte {
+	return []byte{byte(v), byte(v >> 8), byte(v >> 16)}
+}
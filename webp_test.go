@@ -0,0 +1,94 @@
+package golottie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// vp8lFrame returns still WebP with VP8L header of the provided size,
+// the bitstream itself is not valid.
+func vp8lFrame(width, height int, alpha bool) []byte {
+	bits := uint32(width-1) | uint32(height-1)<<14
+	if alpha {
+		bits |= 1 << 28
+	}
+	payload := append([]byte{0x2f}, binary.LittleEndian.AppendUint32(nil, bits)...)
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	writeRIFFChunk(&body, "EXIF", []byte{1, 2})
+	writeRIFFChunk(&body, "VP8L", payload)
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(body.Len()))...), body.Bytes()...)
+}
+
+func Test_WebPSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWebPSink(&buf, WebPOptions{Quality: 80, LoopCount: 2})
+	format, quality := sink.FrameFormat()
+	assert.Equal(t, FormatWebP, format)
+	assert.Equal(t, 80, quality)
+	_, quality = NewWebPSink(&buf, WebPOptions{}).FrameFormat()
+	assert.Equal(t, 100, quality)
+	assert.NoError(t, sink.Open(Metadata{Width: 4, Height: 3, FrameRate: 30}))
+	for i := 2; i >= 0; i-- {
+		assert.NoError(t, sink.WriteFrame(Frame{Num: i, Data: vp8lFrame(4, 3, i == 1)}))
+	}
+	assert.NoError(t, sink.Close())
+
+	data := buf.Bytes()
+	assert.Equal(t, "RIFF", string(data[:4]))
+	assert.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:]))
+	assert.Equal(t, "WEBP", string(data[8:12]))
+
+	var names []string
+	var durations []int
+	for rest := data[12:]; len(rest) >= 8; {
+		name := string(rest[:4])
+		size := int(binary.LittleEndian.Uint32(rest[4:]))
+		payload := rest[8 : 8+size]
+		names = append(names, name)
+		switch name {
+		case "VP8X":
+			assert.Equal(t, byte(webpFlagAnimation|webpFlagAlpha), payload[0])
+			assert.Equal(t, []byte{3, 0, 0, 2, 0, 0}, payload[4:10])
+		case "ANIM":
+			assert.Equal(t, uint16(3), binary.LittleEndian.Uint16(payload[4:]))
+		case "ANMF":
+			assert.Equal(t, []byte{3, 0, 0, 2, 0, 0}, payload[6:12])
+			durations = append(durations, int(payload[12])|int(payload[13])<<8)
+			assert.Equal(t, "VP8L", string(payload[16:20]))
+		}
+		rest = rest[8+size+size&1:]
+	}
+	assert.Equal(t, []string{"VP8X", "ANIM", "ANMF", "ANMF", "ANMF"}, names)
+	assert.Equal(t, []int{33, 34, 33}, durations)
+}
+
+func Test_WebPSink_gaps(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWebPSink(&buf, DefaultWebPOptions)
+	assert.NoError(t, sink.Open(Metadata{Width: 4, Height: 3, FrameRate: 10}))
+	// frames 1 and 2 are missing, frame 0 is held until frame 3
+	for _, num := range []int{0, 3, 4} {
+		assert.NoError(t, sink.WriteFrame(Frame{Num: num, Data: vp8lFrame(4, 3, false)}))
+	}
+	assert.NoError(t, sink.Close())
+	var durations []int
+	for rest := buf.Bytes()[12:]; len(rest) >= 8; {
+		size := int(binary.LittleEndian.Uint32(rest[4:]))
+		if string(rest[:4]) == "ANMF" {
+			durations = append(durations, int(rest[20])|int(rest[21])<<8)
+		}
+		rest = rest[8+size+size&1:]
+	}
+	assert.Equal(t, []int{300, 100, 100}, durations)
+}
+
+func Test_WebPSink_InvalidFrame(t *testing.T) {
+	sink := NewWebPSink(&bytes.Buffer{}, DefaultWebPOptions)
+	assert.NoError(t, sink.Open(Metadata{}))
+	assert.ErrorIs(t, sink.WriteFrame(Frame{Data: []byte("not a webp")}), ErrInvalidWebP)
+	assert.ErrorIs(t, sink.Close(), ErrNoFrames)
+}